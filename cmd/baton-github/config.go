@@ -8,7 +8,6 @@ var (
 	accessTokenField = field.StringField(
 		"token",
 		field.WithDescription("The GitHub access token used to connect to the GitHub API."),
-		field.WithRequired(true),
 	)
 	orgsField = field.StringSliceField(
 		"orgs",
@@ -18,12 +17,96 @@ var (
 		"instance-url",
 		field.WithDescription(`The GitHub instance URL to connect to. (default "https://github.com")`),
 	)
+	enterpriseField = field.StringField(
+		"enterprise",
+		field.WithDescription("The slug of the GitHub Enterprise account the organizations belong to."),
+	)
+	emuField = field.BoolField(
+		"emu",
+		field.WithDescription("Provision accounts through SCIM, for enterprises with managed users."),
+	)
+	inviteRoleField = field.StringField(
+		"invite-role",
+		field.WithDescription(`The org role of accounts created by email invitation: direct_member, admin or billing_manager. (default "direct_member")`),
+	)
+	inviteTeamsField = field.StringSliceField(
+		"invite-teams",
+		field.WithDescription("The slugs of the teams accounts created by email invitation are added to."),
+	)
+	dormantDaysField = field.IntField(
+		"dormant-days",
+		field.WithDescription("Flag users without activity for this many days as dormant. Requires GitHub Enterprise Server or --enterprise."),
+	)
+	userActivityField = field.BoolField(
+		"user-activity",
+		field.WithDescription("Sync the last activity of users from the all users report of GitHub Enterprise Server, and their last login from the enterprise audit log."),
+	)
+	repoIncludeField = field.StringSliceField(
+		"repo-include",
+		field.WithDescription("Glob patterns of the names of the repositories to sync. By default every repository is synced."),
+	)
+	repoExcludeField = field.StringSliceField(
+		"repo-exclude",
+		field.WithDescription("Glob patterns of the names of the repositories to skip."),
+	)
+	skipArchivedReposField = field.BoolField(
+		"skip-archived-repos",
+		field.WithDescription("Skip syncing archived repositories."),
+	)
+	skipForkReposField = field.BoolField(
+		"skip-fork-repos",
+		field.WithDescription("Skip syncing forked repositories."),
+	)
+	repoVisibilityField = field.StringSliceField(
+		"repo-visibility",
+		field.WithDescription("Limit syncing to repositories with these visibilities: public, private or internal."),
+	)
+	repoTopicsField = field.StringSliceField(
+		"repo-topics",
+		field.WithDescription("Limit syncing to repositories with at least one of these topics."),
+	)
+	appIDField = field.StringField(
+		"app-id",
+		field.WithDescription("The ID of the GitHub App used to connect to the GitHub API instead of an access token."),
+	)
+	appPrivateKeyField = field.StringField(
+		"app-privatekey",
+		field.WithDescription("The PEM encoded private key of the GitHub App."),
+	)
+	appInstallationIDsField = field.StringSliceField(
+		"app-installation-ids",
+		field.WithDescription("The GitHub App installation used for each org, as org=installation_id. Orgs without one use the installation the app has on them."),
+	)
 	// configuration defines the external configuration required for the connector to run.
 	configuration = field.Configuration{
 		Fields: []field.SchemaField{
 			accessTokenField,
 			orgsField,
 			instanceUrlField,
+			enterpriseField,
+			emuField,
+			inviteRoleField,
+			inviteTeamsField,
+			dormantDaysField,
+			userActivityField,
+			repoIncludeField,
+			repoExcludeField,
+			skipArchivedReposField,
+			skipForkReposField,
+			repoVisibilityField,
+			repoTopicsField,
+			appIDField,
+			appPrivateKeyField,
+			appInstallationIDsField,
+		},
+		Constraints: []field.SchemaFieldRelationship{
+			field.FieldsAtLeastOneUsed(accessTokenField, appIDField),
+			field.FieldsMutuallyExclusive(accessTokenField, appIDField),
+			field.FieldsRequiredTogether(appIDField, appPrivateKeyField),
+			field.FieldsDependentOn([]field.SchemaField{appInstallationIDsField}, []field.SchemaField{appIDField}),
+			field.FieldsDependentOn([]field.SchemaField{emuField}, []field.SchemaField{enterpriseField}),
+			field.FieldsMutuallyExclusive(emuField, appIDField),
+			field.FieldsMutuallyExclusive(enterpriseField, appIDField),
 		},
 	}
 )
@@ -48,12 +48,26 @@ func getConnector(ctx context.Context, v *viper.Viper) (types.ConnectorServer, e
 		return nil, err
 	}
 
-	cb, err := connector.New(
-		ctx,
-		v.GetStringSlice(orgsField.FieldName),
-		v.GetString(instanceUrlField.FieldName),
-		v.GetString(accessTokenField.FieldName),
-	)
+	cb, err := connector.New(ctx, connector.Config{
+		Orgs:               v.GetStringSlice(orgsField.FieldName),
+		InstanceURL:        v.GetString(instanceUrlField.FieldName),
+		AccessToken:        v.GetString(accessTokenField.FieldName),
+		Enterprise:         v.GetString(enterpriseField.FieldName),
+		EMU:                v.GetBool(emuField.FieldName),
+		InviteRole:         v.GetString(inviteRoleField.FieldName),
+		InviteTeams:        v.GetStringSlice(inviteTeamsField.FieldName),
+		DormantDays:        v.GetInt(dormantDaysField.FieldName),
+		UserActivity:       v.GetBool(userActivityField.FieldName),
+		RepoInclude:        v.GetStringSlice(repoIncludeField.FieldName),
+		RepoExclude:        v.GetStringSlice(repoExcludeField.FieldName),
+		SkipArchivedRepos:  v.GetBool(skipArchivedReposField.FieldName),
+		SkipForkRepos:      v.GetBool(skipForkReposField.FieldName),
+		RepoVisibility:     v.GetStringSlice(repoVisibilityField.FieldName),
+		RepoTopics:         v.GetStringSlice(repoTopicsField.FieldName),
+		AppID:              v.GetString(appIDField.FieldName),
+		AppPrivateKey:      v.GetString(appPrivateKeyField.FieldName),
+		AppInstallationIDs: v.GetStringSlice(appInstallationIDsField.FieldName),
+	})
 	if err != nil {
 		l.Error("error creating connector", zap.Error(err))
 		return nil, err
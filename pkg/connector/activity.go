@@ -0,0 +1,274 @@
+package connector
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v63/github"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+)
+
+const (
+	// defaultActivityDays is how far back sign ins are read when users aren't flagged as dormant.
+	defaultActivityDays = 90
+	// activityMaxAge is how long the activity of users is reused before it's read again, so that it's read once per sync
+	// rather than once per org.
+	activityMaxAge = time.Hour
+	// allUsersReportPath is the site admin report of GitHub Enterprise Server with the last activity of every user.
+	allUsersReportPath = "/stafftools/reports/all_users.csv"
+)
+
+// errAuditLogUnavailable is returned when an audit log can't be read, either because the account isn't on GitHub
+// Enterprise Cloud or because the credentials can't read it.
+var errAuditLogUnavailable = errors.New("github-connector: audit log is unavailable")
+
+// activityConfig configures where the activity of users is read from.
+type activityConfig struct {
+	// dormantDays is the number of days without activity after which users are dormant. Dormant users aren't flagged
+	// when it's zero.
+	dormantDays int
+	// lastActivity is true when the last activity and last login of every user is synced.
+	lastActivity bool
+	// enterprise is the slug of the enterprise whose audit log has the sign in events of users.
+	enterprise string
+	// enterpriseServer is true when syncing GitHub Enterprise Server, whose all users report has the last activity of
+	// every user.
+	enterpriseServer bool
+}
+
+// enabled returns true when the activity of users has to be looked up.
+func (c activityConfig) enabled() bool {
+	return c.dormantDays > 0 || c.lastActivity
+}
+
+// days returns how many days back sign ins are read from the enterprise audit log.
+func (c activityConfig) days() int {
+	if c.dormantDays > 0 {
+		return c.dormantDays
+	}
+
+	return defaultActivityDays
+}
+
+// userActivity is the latest activity of a user. The times are nil when they aren't known.
+type userActivity struct {
+	// lastActivity is the time the user was last active, from the all users report of GitHub Enterprise Server.
+	lastActivity *time.Time
+	// lastLogin is the time the user last signed in, from the enterprise audit log.
+	lastLogin *time.Time
+	// loginsSince is the start of the period the sign ins of the user were read for, nil if they couldn't be read.
+	loginsSince *time.Time
+}
+
+// latest returns the time of the most recent activity of the user.
+func (a *userActivity) latest() *time.Time {
+	if a.lastLogin != nil && (a.lastActivity == nil || a.lastLogin.After(*a.lastActivity)) {
+		return a.lastLogin
+	}
+
+	return a.lastActivity
+}
+
+// isDormant returns true if the user is known to have had no activity for the given number of days. Users whose
+// activity isn't known aren't dormant.
+func (a *userActivity) isDormant(days int) bool {
+	cutoff := time.Now().Add(-time.Duration(days) * 24 * time.Hour)
+	if latest := a.latest(); latest != nil {
+		return latest.Before(cutoff)
+	}
+
+	// A user who didn't sign in during a period that covers the dormant period had no activity in it.
+	return a.loginsSince != nil && !a.loginsSince.After(cutoff)
+}
+
+// activityReport is the activity of every user, read in bulk rather than user by user.
+type activityReport struct {
+	readAt time.Time
+	// lastActivity is the last activity of users by lowercase login, nil if the all users report couldn't be read.
+	lastActivity map[string]time.Time
+	// lastLogin is the last sign in of users by lowercase login, nil if the enterprise audit log couldn't be read.
+	lastLogin map[string]time.Time
+	// loginsSince is the start of the period sign ins were read for.
+	loginsSince time.Time
+}
+
+// activity returns the activity of the user.
+func (r *activityReport) activity(login string) *userActivity {
+	login = strings.ToLower(login)
+	rv := &userActivity{}
+	if lastActivity, ok := r.lastActivity[login]; ok {
+		rv.lastActivity = &lastActivity
+	}
+	if r.lastLogin != nil {
+		rv.loginsSince = &r.loginsSince
+		if lastLogin, ok := r.lastLogin[login]; ok {
+			rv.lastLogin = &lastLogin
+		}
+	}
+
+	return rv
+}
+
+// readActivityReport reads the activity of every user from the sources the config enables.
+func readActivityReport(ctx context.Context, client *github.Client, cfg activityConfig) (*activityReport, error) {
+	l := ctxzap.Extract(ctx)
+	rv := &activityReport{readAt: time.Now()}
+
+	if cfg.enterpriseServer {
+		lastActivity, err := readAllUsersReport(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+		if lastActivity == nil {
+			l.Warn("the all users report is unavailable, not syncing the last activity of users from it")
+		}
+		rv.lastActivity = lastActivity
+	}
+
+	if cfg.enterprise != "" {
+		rv.loginsSince = rv.readAt.Add(-time.Duration(cfg.days()) * 24 * time.Hour)
+		source := &auditLogSource{key: "enterprise:" + cfg.enterprise, enterprise: cfg.enterprise, client: client}
+		lastLogin, err := lastLogins(ctx, source, rv.loginsSince)
+		if err != nil {
+			if !errors.Is(err, errAuditLogUnavailable) {
+				return nil, err
+			}
+			l.Warn("audit log is unavailable, not syncing the last login of users from it", zap.String("source", source.key))
+		}
+		rv.lastLogin = lastLogin
+	}
+
+	return rv, nil
+}
+
+// readAllUsersReport returns the last activity of every user from the all users report of GitHub Enterprise Server.
+// It returns nil if the report can't be read, either because the credentials aren't a site admin's or because the
+// report is still being generated.
+func readAllUsersReport(ctx context.Context, client *github.Client) (map[string]time.Time, error) {
+	req, err := client.NewRequest(http.MethodGet, allUsersReportPath, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/csv")
+
+	var buf bytes.Buffer
+	resp, err := client.Do(ctx, req, &buf)
+	if err != nil {
+		var acceptedErr *github.AcceptedError
+		if errors.As(err, &acceptedErr) ||
+			(resp != nil && (resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusForbidden)) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("github-connector: failed to read the all users report: %w", err)
+	}
+
+	return parseAllUsersReport(&buf)
+}
+
+// parseAllUsersReport returns the last active time of the users in the all users report, leaving out users who were
+// never active.
+func parseAllUsersReport(r io.Reader) (map[string]time.Time, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("github-connector: failed to parse the all users report: %w", err)
+	}
+	if len(records) == 0 {
+		return map[string]time.Time{}, nil
+	}
+
+	loginColumn, lastActiveColumn := -1, -1
+	for i, column := range records[0] {
+		switch strings.TrimSpace(column) {
+		case "login":
+			loginColumn = i
+		case "last_active":
+			lastActiveColumn = i
+		}
+	}
+	if loginColumn == -1 || lastActiveColumn == -1 {
+		return nil, fmt.Errorf("github-connector: the all users report has no login or last_active column")
+	}
+
+	rv := make(map[string]time.Time, len(records)-1)
+	for _, record := range records[1:] {
+		if len(record) <= loginColumn || len(record) <= lastActiveColumn {
+			continue
+		}
+		lastActive, ok := parseReportTime(record[lastActiveColumn])
+		if !ok {
+			continue
+		}
+		rv[strings.ToLower(record[loginColumn])] = lastActive
+	}
+
+	return rv, nil
+}
+
+// reportTimeLayouts are the layouts of the times in the site admin reports.
+var reportTimeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05 MST",
+	"2006-01-02 15:04:05",
+}
+
+func parseReportTime(value string) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, false
+	}
+
+	for _, layout := range reportTimeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// lastLogins returns the time each user last signed in since the given time, by lowercase login. It reads every sign
+// in from the audit log in a single search, rather than one search per user.
+func lastLogins(ctx context.Context, source *auditLogSource, since time.Time) (map[string]time.Time, error) {
+	opts := &github.GetAuditLogOptions{
+		Phrase: github.String(fmt.Sprintf("action:user.login created:>=%s", since.UTC().Format(time.RFC3339))),
+		ListCursorOptions: github.ListCursorOptions{
+			PerPage: auditLogPageSize,
+		},
+	}
+
+	rv := make(map[string]time.Time)
+	for {
+		entries, resp, err := source.getAuditLog(ctx, opts)
+		if err != nil {
+			if resp != nil && (resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusForbidden) {
+				return nil, errAuditLogUnavailable
+			}
+			return nil, fmt.Errorf("github-connector: failed to search audit log for %s: %w", source.key, err)
+		}
+
+		for _, entry := range entries {
+			login := strings.ToLower(entry.GetActor())
+			if login == "" {
+				continue
+			}
+			occurredAt := auditEntryTime(entry)
+			if latest, ok := rv[login]; !ok || occurredAt.After(latest) {
+				rv[login] = occurredAt
+			}
+		}
+
+		if resp.After == "" {
+			return rv, nil
+		}
+		opts.After = resp.After
+	}
+}
@@ -0,0 +1,212 @@
+package connector
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v63/github"
+	"golang.org/x/oauth2"
+)
+
+const (
+	// GitHub rejects app JWTs that are valid for longer than ten minutes.
+	appJWTLifetime = 9 * time.Minute
+	// Backdate the JWT to allow for clock drift between us and GitHub.
+	appJWTClockSkew = 60 * time.Second
+	// Installation tokens are valid for one hour, refresh them well before they expire.
+	installationTokenEarlyExpiry = 5 * time.Minute
+)
+
+// appJWTTokenSource mints JWTs signed with the GitHub App private key. These are only valid for the app endpoints.
+type appJWTTokenSource struct {
+	appID      string
+	privateKey *rsa.PrivateKey
+}
+
+func (ts *appJWTTokenSource) Token() (*oauth2.Token, error) {
+	now := time.Now()
+	expiresAt := now.Add(appJWTLifetime)
+
+	header, err := json.Marshal(map[string]string{
+		"alg": "RS256",
+		"typ": "JWT",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	claims, err := json.Marshal(map[string]interface{}{
+		"iat": now.Add(-appJWTClockSkew).Unix(),
+		"exp": expiresAt.Unix(),
+		"iss": ts.appID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, ts.privateKey, crypto.SHA256, digest[:])
+	if err != nil {
+		return nil, fmt.Errorf("github-connector: failed to sign app jwt: %w", err)
+	}
+
+	return &oauth2.Token{
+		AccessToken: unsigned + "." + base64.RawURLEncoding.EncodeToString(signature),
+		TokenType:   "Bearer",
+		Expiry:      expiresAt,
+	}, nil
+}
+
+// appInstallationTokenSource exchanges an app JWT for an installation access token.
+type appInstallationTokenSource struct {
+	appClient      *github.Client
+	installationID int64
+}
+
+func (ts *appInstallationTokenSource) Token() (*oauth2.Token, error) {
+	// Tokens are refreshed long after the connector is created, so the refresh doesn't use the context it was created
+	// with, which may be cancelled by then.
+	token, _, err := ts.appClient.Apps.CreateInstallationToken(context.Background(), ts.installationID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("github-connector: failed to create installation token for installation %d: %w", ts.installationID, err)
+	}
+
+	return &oauth2.Token{
+		AccessToken: token.GetToken(),
+		TokenType:   "Bearer",
+		Expiry:      token.GetExpiresAt().Time,
+	}, nil
+}
+
+// newAppJWTTokenSource returns a caching token source that signs app JWTs with the given PEM encoded private key.
+func newAppJWTTokenSource(appID string, privateKeyPEM string) (oauth2.TokenSource, error) {
+	privateKey, err := parseAppPrivateKey(privateKeyPEM)
+	if err != nil {
+		return nil, err
+	}
+
+	return oauth2.ReuseTokenSourceWithExpiry(nil, &appJWTTokenSource{
+		appID:      appID,
+		privateKey: privateKey,
+	}, appJWTClockSkew), nil
+}
+
+// newAppInstallationTokenSource returns a caching token source that refreshes the installation token before it expires.
+// The same token source should be shared between the REST and GraphQL clients so that they refresh together.
+func newAppInstallationTokenSource(appClient *github.Client, installationID int64) oauth2.TokenSource {
+	return oauth2.ReuseTokenSourceWithExpiry(nil, &appInstallationTokenSource{
+		appClient:      appClient,
+		installationID: installationID,
+	}, installationTokenEarlyExpiry)
+}
+
+func parseAppPrivateKey(privateKeyPEM string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(strings.TrimSpace(privateKeyPEM)))
+	if block == nil {
+		return nil, fmt.Errorf("github-connector: app private key is not PEM encoded")
+	}
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		rsaKey, ok := key.(*rsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("github-connector: app private key must be an RSA key")
+		}
+		return rsaKey, nil
+	default:
+		return nil, fmt.Errorf("github-connector: unsupported app private key type: %s", block.Type)
+	}
+}
+
+// parseAppInstallationIDs parses org=installation_id pairs into the installation ID of each org.
+func parseAppInstallationIDs(values []string) (map[string]int64, error) {
+	rv := make(map[string]int64, len(values))
+	for _, value := range values {
+		org, id, ok := strings.Cut(value, "=")
+		installationID, err := strconv.ParseInt(strings.TrimSpace(id), 10, 64)
+		if !ok || strings.TrimSpace(org) == "" || err != nil {
+			return nil, fmt.Errorf("github-connector: invalid app installation ID %s, must be org=installation_id", value)
+		}
+		rv[strings.TrimSpace(org)] = installationID
+	}
+
+	return rv, nil
+}
+
+// listAppInstallations returns the org installations to sync. Orgs with a configured installation ID use that
+// installation. Every other org the app is installed on is found from its installations, limited to the configured
+// orgs, unless every configured org has an installation ID.
+func listAppInstallations(ctx context.Context, appClient *github.Client, installationIDs map[string]int64, orgs []string) ([]*github.Installation, error) {
+	rv := make([]*github.Installation, 0)
+	configuredOrgs := make([]string, 0, len(installationIDs))
+	for org := range installationIDs {
+		configuredOrgs = append(configuredOrgs, org)
+	}
+	slices.Sort(configuredOrgs)
+	for _, org := range configuredOrgs {
+		installation, _, err := appClient.Apps.GetInstallation(ctx, installationIDs[org])
+		if err != nil {
+			return nil, fmt.Errorf("github-connector: failed to get app installation %d: %w", installationIDs[org], err)
+		}
+		if !strings.EqualFold(installation.GetAccount().GetLogin(), org) {
+			return nil, fmt.Errorf("github-connector: app installation %d is on %s, not %s", installation.GetID(), installation.GetAccount().GetLogin(), org)
+		}
+		rv = append(rv, installation)
+	}
+
+	if len(orgs) > 0 && !slices.ContainsFunc(orgs, func(org string) bool {
+		_, ok := installationIDs[org]
+		return !ok
+	}) {
+		return rv, nil
+	}
+
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		installations, resp, err := appClient.Apps.ListInstallations(ctx, opts)
+		if err != nil {
+			return nil, fmt.Errorf("github-connector: failed to list app installations: %w", err)
+		}
+
+		for _, installation := range installations {
+			if installation.GetTargetType() != "Organization" {
+				continue
+			}
+			if len(orgs) > 0 && !slices.Contains(orgs, installation.GetAccount().GetLogin()) {
+				continue
+			}
+			if slices.ContainsFunc(configuredOrgs, func(org string) bool {
+				return strings.EqualFold(org, installation.GetAccount().GetLogin())
+			}) {
+				continue
+			}
+			rv = append(rv, installation)
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return rv, nil
+}
@@ -0,0 +1,109 @@
+package connector
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"strings"
+	"testing"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/google/go-github/v63/github"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppJWTTokenSource(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.Nil(t, err)
+
+	privateKeyPEM := pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(key),
+	})
+
+	ts, err := newAppJWTTokenSource("1234", string(privateKeyPEM))
+	require.Nil(t, err)
+
+	token, err := ts.Token()
+	require.Nil(t, err)
+
+	parts := strings.Split(token.AccessToken, ".")
+	require.Len(t, parts, 3)
+
+	claimsJSON, err := base64.RawURLEncoding.DecodeString(parts[1])
+	require.Nil(t, err)
+
+	claims := struct {
+		Iat int64  `json:"iat"`
+		Exp int64  `json:"exp"`
+		Iss string `json:"iss"`
+	}{}
+	err = json.Unmarshal(claimsJSON, &claims)
+	require.Nil(t, err)
+	require.Equal(t, "1234", claims.Iss)
+	require.LessOrEqual(t, claims.Exp-claims.Iat, int64(600))
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	require.Nil(t, err)
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	require.Nil(t, rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], signature))
+
+	t.Run("should reject keys that are not PEM encoded", func(t *testing.T) {
+		_, err := newAppJWTTokenSource("1234", "not a key")
+		require.NotNil(t, err)
+	})
+}
+
+func TestAppInstallationOrgClients(t *testing.T) {
+	ctx := context.Background()
+
+	installationClient := github.NewClient(nil)
+	cache := newOrgNameCache(nil)
+	cache.SetOrgClient(12, "organization-12", installationClient, nil)
+
+	installedOrg := &v2.ResourceId{ResourceType: resourceTypeOrg.Id, Resource: "12"}
+	client, err := cache.GetClient(installedOrg)
+	require.Nil(t, err)
+	require.Same(t, installationClient, client)
+
+	orgName, err := cache.GetOrgName(ctx, installedOrg)
+	require.Nil(t, err)
+	require.Equal(t, "organization-12", orgName)
+
+	otherOrg := &v2.ResourceId{ResourceType: resourceTypeOrg.Id, Resource: "34"}
+	_, err = cache.GetClient(otherOrg)
+	require.ErrorContains(t, err, "isn't installed on org 34")
+
+	_, err = cache.GetOrgName(ctx, otherOrg)
+	require.ErrorContains(t, err, "isn't installed on org 34")
+
+	orgIDs, err := cache.OrgIDs(ctx)
+	require.Nil(t, err)
+	require.Equal(t, []*v2.ResourceId{installedOrg}, orgIDs)
+}
+
+func TestAppRejectsEnterprise(t *testing.T) {
+	_, err := New(context.Background(), Config{
+		AppID:         "1234",
+		AppPrivateKey: "key",
+		Enterprise:    "acme",
+	})
+	require.ErrorContains(t, err, "enterprise can't be synced")
+}
+
+func TestParseAppInstallationIDs(t *testing.T) {
+	installationIDs, err := parseAppInstallationIDs([]string{"acme=1234", " widgets = 5678 "})
+	require.Nil(t, err)
+	require.Equal(t, map[string]int64{"acme": 1234, "widgets": 5678}, installationIDs)
+
+	for _, value := range []string{"1234", "acme=", "=1234", "acme=abc"} {
+		_, err = parseAppInstallationIDs([]string{value})
+		require.ErrorContains(t, err, "must be org=installation_id", value)
+	}
+}
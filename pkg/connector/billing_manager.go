@@ -0,0 +1,40 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/google/go-github/v63/github"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+)
+
+// listBillingManagers returns the billing managers of the org. go-github has no method to list the billing managers of
+// an org, so the endpoint is requested directly. It returns nil if the credentials can't list them.
+func listBillingManagers(ctx context.Context, client *github.Client, orgName string) ([]*github.User, error) {
+	var rv []*github.User
+	page := 1
+	for {
+		req, err := client.NewRequest(http.MethodGet, fmt.Sprintf("orgs/%s/billing_managers?per_page=100&page=%d", orgName, page), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var users []*github.User
+		resp, err := client.Do(ctx, req, &users)
+		if err != nil {
+			if resp != nil && (resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusForbidden) {
+				ctxzap.Extract(ctx).Warn("can't list billing managers", zap.String("org", orgName), zap.Error(err))
+				return nil, nil
+			}
+			return nil, fmt.Errorf("github-connector: failed to list billing managers of %s: %w", orgName, err)
+		}
+		rv = append(rv, users...)
+
+		if resp.NextPage == 0 {
+			return rv, nil
+		}
+		page = resp.NextPage
+	}
+}
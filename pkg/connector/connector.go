@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"slices"
 	"strings"
+	"sync"
 
 	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
 	"github.com/conductorone/baton-sdk/pkg/annotations"
@@ -24,6 +26,11 @@ const githubDotCom = "https://github.com"
 var ValidAssetDomains = []string{"avatars.githubusercontent.com"}
 
 var (
+	resourceTypeEnterprise = &v2.ResourceType{
+		Id:          "enterprise",
+		DisplayName: "Enterprise",
+		Annotations: v1AnnotationsForResourceType("enterprise"),
+	}
 	resourceTypeOrg = &v2.ResourceType{
 		Id:          "org",
 		DisplayName: "Org",
@@ -38,8 +45,27 @@ var (
 	resourceTypeRepository = &v2.ResourceType{
 		Id:          "repository",
 		DisplayName: "Repository",
+		Traits:      []v2.ResourceType_Trait{v2.ResourceType_TRAIT_APP},
 		Annotations: v1AnnotationsForResourceType("repository"),
 	}
+	resourceTypeOrgRole = &v2.ResourceType{
+		Id:          "org_role",
+		DisplayName: "Org Role",
+		Traits:      []v2.ResourceType_Trait{v2.ResourceType_TRAIT_ROLE},
+		Annotations: v1AnnotationsForResourceType("org_role"),
+	}
+	resourceTypeIDPGroup = &v2.ResourceType{
+		Id:          "idp_group",
+		DisplayName: "IdP Group",
+		Traits:      []v2.ResourceType_Trait{v2.ResourceType_TRAIT_GROUP},
+		Annotations: v1AnnotationsForResourceType("idp_group"),
+	}
+	resourceTypeInvitation = &v2.ResourceType{
+		Id:          "invitation",
+		DisplayName: "Invitation",
+		Traits:      []v2.ResourceType_Trait{v2.ResourceType_TRAIT_USER},
+		Annotations: v1AnnotationsForResourceType("invitation"),
+	}
 	resourceTypeUser = &v2.ResourceType{
 		Id:          "user",
 		DisplayName: "User",
@@ -50,22 +76,94 @@ var (
 	}
 )
 
+// Config holds the settings used to construct the GitHub connector.
+type Config struct {
+	Orgs        []string
+	InstanceURL string
+	AccessToken string
+	// Enterprise is the slug of the GitHub Enterprise account the orgs belong to.
+	Enterprise string
+	// EMU is true when the enterprise has managed users, which are provisioned through SCIM.
+	EMU bool
+	// InviteRole and InviteTeams are the org role and team slugs of accounts created by email invitation.
+	InviteRole  string
+	InviteTeams []string
+	// DormantDays is the number of days without activity after which users are flagged as dormant.
+	DormantDays int
+	// UserActivity is true when the last activity and last login of users is synced.
+	UserActivity bool
+	// RepoInclude and RepoExclude are glob patterns of the names of the repositories that are synced or skipped.
+	RepoInclude []string
+	RepoExclude []string
+	// SkipArchivedRepos and SkipForkRepos skip syncing archived and forked repositories.
+	SkipArchivedRepos bool
+	SkipForkRepos     bool
+	// RepoVisibility and RepoTopics limit syncing to repositories with one of the visibilities and one of the topics.
+	RepoVisibility []string
+	RepoTopics     []string
+
+	// GitHub App credentials, used instead of AccessToken when AppID is set.
+	AppID         string
+	AppPrivateKey string
+	// AppInstallationIDs are org=installation_id pairs of the installation used for each org. Orgs without one use the
+	// installation the app has on them.
+	AppInstallationIDs []string
+}
+
 type GitHub struct {
-	orgs           []string
-	client         *github.Client
-	instanceURL    string
-	graphqlClient  *githubv4.Client
-	hasSAMLEnabled *bool
-	orgCache       *orgNameCache
+	orgs             []string
+	enterprise       string
+	emu              bool
+	inviteRole       string
+	inviteTeams      []string
+	dormantDays      int
+	userActivity     bool
+	client           *github.Client
+	appClient        *github.Client
+	appInstallations []*github.Installation
+	instanceURL      string
+	graphqlClient    *githubv4.Client
+	hasSAMLEnabled   *bool
+	orgCache         *orgNameCache
+	repoFilter       repositoryFilter
+
+	// auditLogLookupsMtx guards auditLogLookups, the team and repository lookups of each audit log of the event feed.
+	auditLogLookupsMtx sync.Mutex
+	auditLogLookups    map[string]*auditLogLookup
 }
 
 func (gh *GitHub) ResourceSyncers(ctx context.Context) []connectorbuilder.ResourceSyncer {
-	return []connectorbuilder.ResourceSyncer{
-		orgBuilder(gh.client, gh.orgCache, gh.orgs),
+	var enterprise *enterpriseResourceType
+	if gh.enterprise != "" {
+		enterprise = enterpriseBuilder(gh.graphqlClient, gh.orgCache, gh.enterprise)
+	}
+
+	rv := []connectorbuilder.ResourceSyncer{
+		orgBuilder(gh.client, gh.orgCache, gh.orgs, gh.appInstallations, enterprise),
 		teamBuilder(gh.client, gh.orgCache),
-		userBuilder(gh.client, gh.hasSAMLEnabled, gh.graphqlClient, gh.orgCache),
-		repositoryBuilder(gh.client, gh.orgCache),
+		userBuilder(gh.client, gh.hasSAMLEnabled, gh.graphqlClient, gh.orgCache, accountConfig{
+			enterprise:  gh.enterprise,
+			emu:         gh.emu,
+			orgs:        gh.orgs,
+			inviteRole:  gh.inviteRole,
+			inviteTeams: gh.inviteTeams,
+		}, activityConfig{
+			dormantDays:      gh.dormantDays,
+			lastActivity:     gh.userActivity,
+			enterprise:       gh.enterprise,
+			enterpriseServer: isEnterpriseServer(gh.instanceURL),
+		}),
+		repositoryBuilder(gh.client, gh.orgCache, gh.repoFilter),
+		orgRoleBuilder(gh.client, gh.orgCache),
+		invitationBuilder(gh.client, gh.orgCache),
+		idpGroupBuilder(gh.client, gh.orgCache),
 	}
+
+	if enterprise != nil {
+		rv = append(rv, enterprise)
+	}
+
+	return rv
 }
 
 // Metadata returns metadata about the connector.
@@ -77,6 +175,10 @@ func (gh *GitHub) Metadata(ctx context.Context) (*v2.ConnectorMetadata, error) {
 
 // Validate hits the GitHub API to validate that the configured credentials are still valid.
 func (gh *GitHub) Validate(ctx context.Context) (annotations.Annotations, error) {
+	if gh.appClient != nil {
+		return gh.validateAppInstallations(ctx)
+	}
+
 	page := 0
 	orgLogins := gh.orgs
 	filterOrgs := true
@@ -131,8 +233,59 @@ func (gh *GitHub) Validate(ctx context.Context) (annotations.Annotations, error)
 	return nil, nil
 }
 
-// newGitHubClient returns a new GitHub API client authenticated with an access token via oauth2.
-func newGitHubClient(ctx context.Context, instanceURL string, accessToken string) (*github.Client, error) {
+// validateAppInstallations checks that the app installations are usable and have been granted the permissions the
+// connector needs. Installation tokens are not tied to a user, so there is no org admin membership to check.
+func (gh *GitHub) validateAppInstallations(ctx context.Context) (annotations.Annotations, error) {
+	installedOrgs := make(map[string]struct{})
+	for _, i := range gh.appInstallations {
+		installation, resp, err := gh.appClient.Apps.GetInstallation(ctx, i.GetID())
+		if err != nil {
+			if resp != nil && resp.StatusCode == http.StatusUnauthorized {
+				return nil, status.Error(codes.Unauthenticated, "github app credentials are not authorized")
+			}
+			return nil, fmt.Errorf("github-connector: failed to retrieve app installation: %w", err)
+		}
+
+		orgLogin := installation.GetAccount().GetLogin()
+		if installation.GetTargetType() != "Organization" {
+			return nil, fmt.Errorf("github app installation %d must be installed on an organization", installation.GetID())
+		}
+
+		if installation.SuspendedAt != nil {
+			return nil, fmt.Errorf("github app installation on the %s organization is suspended", orgLogin)
+		}
+
+		if len(gh.orgs) > 0 && !slices.Contains(gh.orgs, orgLogin) {
+			return nil, fmt.Errorf("github app installation on the %s organization does not match the configured orgs", orgLogin)
+		}
+
+		if !hasAppPermission(installation.GetPermissions().GetMembers()) {
+			return nil, fmt.Errorf("github app must be granted the members permission on the %s organization", orgLogin)
+		}
+
+		installedOrgs[orgLogin] = struct{}{}
+	}
+
+	for _, o := range gh.orgs {
+		if _, ok := installedOrgs[o]; !ok {
+			return nil, fmt.Errorf("github app must be installed on the %s organization", o)
+		}
+	}
+
+	if len(installedOrgs) == 0 {
+		return nil, fmt.Errorf("github app must be installed on at least one organization")
+	}
+
+	return nil, nil
+}
+
+// hasAppPermission returns true if an app installation permission level allows reads.
+func hasAppPermission(level string) bool {
+	return level == "read" || level == "write"
+}
+
+// newGitHubClient returns a new GitHub API client authenticated with the given token source via oauth2.
+func newGitHubClient(ctx context.Context, instanceURL string, ts oauth2.TokenSource) (*github.Client, error) {
 	httpClient, err := uhttp.NewClient(ctx, uhttp.WithLogger(true, ctxzap.Extract(ctx)))
 	if err != nil {
 		return nil, err
@@ -140,42 +293,131 @@ func newGitHubClient(ctx context.Context, instanceURL string, accessToken string
 
 	ctx = context.WithValue(ctx, oauth2.HTTPClient, httpClient)
 
-	ts := oauth2.StaticTokenSource(
-		&oauth2.Token{AccessToken: accessToken},
-	)
 	tc := oauth2.NewClient(ctx, ts)
 	gc := github.NewClient(tc)
 
 	instanceURL = strings.TrimSuffix(instanceURL, "/")
-	if instanceURL != "" && instanceURL != githubDotCom {
+	if isEnterpriseServer(instanceURL) {
 		return gc.WithEnterpriseURLs(instanceURL, instanceURL)
 	}
 
 	return gc, nil
 }
 
+// isEnterpriseServer returns true if the instance URL is a GitHub Enterprise Server rather than github.com.
+func isEnterpriseServer(instanceURL string) bool {
+	instanceURL = strings.TrimSuffix(instanceURL, "/")
+	return instanceURL != "" && instanceURL != githubDotCom
+}
+
 // New returns the GitHub connector configured to sync against the instance URL.
-func New(ctx context.Context, githubOrgs []string, instanceURL, accessToken string) (*GitHub, error) {
-	client, err := newGitHubClient(ctx, instanceURL, accessToken)
+func New(ctx context.Context, cfg Config) (*GitHub, error) {
+	gh := &GitHub{
+		instanceURL:  cfg.InstanceURL,
+		orgs:         cfg.Orgs,
+		enterprise:   cfg.Enterprise,
+		emu:          cfg.EMU,
+		inviteRole:   cfg.InviteRole,
+		inviteTeams:  cfg.InviteTeams,
+		dormantDays:  cfg.DormantDays,
+		userActivity: cfg.UserActivity,
+	}
+
+	switch cfg.InviteRole {
+	case "", orgRoleDirectMember, orgRoleAdmin, orgRoleBillingManager:
+	default:
+		return nil, fmt.Errorf("github-connector: invalid invite role %s, must be one of %s, %s or %s",
+			cfg.InviteRole, orgRoleDirectMember, orgRoleAdmin, orgRoleBillingManager)
+	}
+
+	repoFilter, err := newRepositoryFilter(cfg)
 	if err != nil {
 		return nil, err
 	}
-	graphqlClient, err := newGitHubGraphqlClient(ctx, instanceURL, accessToken)
+	gh.repoFilter = repoFilter
+
+	if cfg.AppID != "" {
+		// Enterprise accounts can't be read with the installation tokens of org installations.
+		if cfg.Enterprise != "" {
+			return nil, fmt.Errorf("github-connector: an enterprise can't be synced when authenticating as a GitHub App")
+		}
+		if cfg.EMU {
+			return nil, fmt.Errorf("github-connector: managed users can't be provisioned when authenticating as a GitHub App")
+		}
+
+		err := gh.setupAppInstallations(ctx, cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		return gh, nil
+	}
+
+	ts := oauth2.StaticTokenSource(
+		&oauth2.Token{AccessToken: cfg.AccessToken},
+	)
+	client, err := newGitHubClient(ctx, cfg.InstanceURL, ts)
 	if err != nil {
 		return nil, err
 	}
-	gh := &GitHub{
-		client:        client,
-		instanceURL:   instanceURL,
-		orgs:          githubOrgs,
-		graphqlClient: graphqlClient,
-		orgCache:      newOrgNameCache(client),
+	graphqlClient, err := newGitHubGraphqlClient(ctx, cfg.InstanceURL, ts)
+	if err != nil {
+		return nil, err
 	}
 
+	gh.client = client
+	gh.graphqlClient = graphqlClient
+	gh.orgCache = newOrgNameCache(client, cfg.Orgs...)
+
 	return gh, nil
 }
 
-func newGitHubGraphqlClient(ctx context.Context, instanceURL string, accessToken string) (*githubv4.Client, error) {
+// setupAppInstallations creates a client for every org the GitHub App is installed on, each authenticated with its
+// own installation token. Requests for orgs the app isn't installed on fail.
+func (gh *GitHub) setupAppInstallations(ctx context.Context, cfg Config) error {
+	jwtSource, err := newAppJWTTokenSource(cfg.AppID, cfg.AppPrivateKey)
+	if err != nil {
+		return err
+	}
+
+	gh.appClient, err = newGitHubClient(ctx, cfg.InstanceURL, jwtSource)
+	if err != nil {
+		return err
+	}
+
+	installationIDs, err := parseAppInstallationIDs(cfg.AppInstallationIDs)
+	if err != nil {
+		return err
+	}
+
+	gh.appInstallations, err = listAppInstallations(ctx, gh.appClient, installationIDs, cfg.Orgs)
+	if err != nil {
+		return err
+	}
+
+	// The app's own token can only manage the app, so every request for org data uses the installation of the org.
+	gh.orgCache = newOrgNameCache(nil)
+
+	for _, installation := range gh.appInstallations {
+		ts := newAppInstallationTokenSource(gh.appClient, installation.GetID())
+
+		client, err := newGitHubClient(ctx, cfg.InstanceURL, ts)
+		if err != nil {
+			return err
+		}
+		graphqlClient, err := newGitHubGraphqlClient(ctx, cfg.InstanceURL, ts)
+		if err != nil {
+			return err
+		}
+
+		org := installation.GetAccount()
+		gh.orgCache.SetOrgClient(org.GetID(), org.GetLogin(), client, graphqlClient)
+	}
+
+	return nil
+}
+
+func newGitHubGraphqlClient(ctx context.Context, instanceURL string, ts oauth2.TokenSource) (*githubv4.Client, error) {
 	httpClient, err := uhttp.NewClient(ctx, uhttp.WithLogger(true, ctxzap.Extract(ctx)))
 	if err != nil {
 		return nil, err
@@ -183,13 +425,10 @@ func newGitHubGraphqlClient(ctx context.Context, instanceURL string, accessToken
 
 	ctx = context.WithValue(ctx, oauth2.HTTPClient, httpClient)
 
-	ts := oauth2.StaticTokenSource(
-		&oauth2.Token{AccessToken: accessToken},
-	)
 	tc := oauth2.NewClient(ctx, ts)
 
 	instanceURL = strings.TrimSuffix(instanceURL, "/")
-	if instanceURL != "" && instanceURL != githubDotCom {
+	if isEnterpriseServer(instanceURL) {
 		gqlURL, err := url.Parse(instanceURL)
 		if err != nil {
 			return nil, err
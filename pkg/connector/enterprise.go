@@ -0,0 +1,413 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/conductorone/baton-sdk/pkg/types/grant"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"github.com/shurcooL/githubv4"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	enterpriseRoleOwner          = "owner"
+	enterpriseRoleBillingManager = "billing_manager"
+	enterpriseRoleMember         = "member"
+
+	enterprisePageSize = 100
+)
+
+type enterpriseUser struct {
+	DatabaseId int64
+	Login      string
+	Name       string
+	Email      string
+}
+
+type graphqlPageInfo struct {
+	EndCursor   githubv4.String
+	HasNextPage bool
+}
+
+type graphqlRateLimit struct {
+	Limit     int
+	Cost      int
+	Remaining int
+	ResetAt   githubv4.DateTime
+}
+
+type enterpriseQuery struct {
+	Enterprise struct {
+		DatabaseId int64
+		Name       string
+		Slug       string
+		Url        string
+	} `graphql:"enterprise(slug: $enterpriseSlug)"`
+}
+
+type enterpriseOrganizationsQuery struct {
+	Enterprise struct {
+		Organizations struct {
+			Nodes []struct {
+				Login string
+			}
+			PageInfo graphqlPageInfo
+		} `graphql:"organizations(first: $pageSize, after: $cursor)"`
+	} `graphql:"enterprise(slug: $enterpriseSlug)"`
+}
+
+type enterpriseAdminsQuery struct {
+	Enterprise struct {
+		OwnerInfo struct {
+			Admins struct {
+				Nodes    []enterpriseUser
+				PageInfo graphqlPageInfo
+			} `graphql:"admins(first: $pageSize, after: $cursor)"`
+		}
+	} `graphql:"enterprise(slug: $enterpriseSlug)"`
+	RateLimit graphqlRateLimit
+}
+
+type enterpriseBillingManagersQuery struct {
+	Enterprise struct {
+		OwnerInfo struct {
+			BillingManagers struct {
+				Nodes    []enterpriseUser
+				PageInfo graphqlPageInfo
+			} `graphql:"billingManagers(first: $pageSize, after: $cursor)"`
+		}
+	} `graphql:"enterprise(slug: $enterpriseSlug)"`
+	RateLimit graphqlRateLimit
+}
+
+// enterpriseMember is either a user account of the enterprise, or a user that is a member of one of its orgs.
+type enterpriseMember struct {
+	EnterpriseUserAccount struct {
+		User enterpriseUser
+	} `graphql:"... on EnterpriseUserAccount"`
+	User enterpriseUser `graphql:"... on User"`
+}
+
+// user returns the user of the member, which has no database ID for user accounts that were never linked to a user.
+func (m *enterpriseMember) user() enterpriseUser {
+	if m.User.DatabaseId != 0 {
+		return m.User
+	}
+	return m.EnterpriseUserAccount.User
+}
+
+type enterpriseMembersQuery struct {
+	Enterprise struct {
+		Members struct {
+			Nodes    []enterpriseMember
+			PageInfo graphqlPageInfo
+		} `graphql:"members(first: $pageSize, after: $cursor)"`
+	} `graphql:"enterprise(slug: $enterpriseSlug)"`
+	RateLimit graphqlRateLimit
+}
+
+type enterpriseTwoFactorMembersQuery struct {
+	Enterprise struct {
+		Members struct {
+			Nodes    []enterpriseMember
+			PageInfo graphqlPageInfo
+		} `graphql:"members(first: $pageSize, after: $cursor, hasTwoFactorEnabled: $hasTwoFactorEnabled)"`
+	} `graphql:"enterprise(slug: $enterpriseSlug)"`
+}
+
+func enterpriseResource(databaseID int64, name string, slug string, url string) (*v2.Resource, error) {
+	return resource.NewResource(
+		name,
+		resourceTypeEnterprise,
+		databaseID,
+		resource.WithAnnotation(
+			&v2.ExternalLink{Url: url},
+			&v2.V1Identifier{Id: fmt.Sprintf("enterprise:%d", databaseID)},
+			&v2.ChildResourceType{ResourceTypeId: resourceTypeOrg.Id},
+		),
+	)
+}
+
+type enterpriseResourceType struct {
+	resourceType  *v2.ResourceType
+	graphqlClient *githubv4.Client
+	orgCache      *orgNameCache
+	slug          string
+
+	orgsMtx sync.Mutex
+	orgs    map[string]struct{}
+}
+
+func (e *enterpriseResourceType) ResourceType(_ context.Context) *v2.ResourceType {
+	return e.resourceType
+}
+
+func (e *enterpriseResourceType) List(ctx context.Context, parentID *v2.ResourceId, _ *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	if parentID != nil {
+		return nil, "", nil, nil
+	}
+
+	q := enterpriseQuery{}
+	err := e.graphqlClient.Query(ctx, &q, map[string]interface{}{
+		"enterpriseSlug": githubv4.String(e.slug),
+	})
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("github-connector: failed to get enterprise %s: %w", e.slug, err)
+	}
+
+	er, err := enterpriseResource(q.Enterprise.DatabaseId, q.Enterprise.Name, q.Enterprise.Slug, q.Enterprise.Url)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	return []*v2.Resource{er}, "", nil, nil
+}
+
+func (e *enterpriseResourceType) Entitlements(_ context.Context, resource *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	return []*v2.Entitlement{
+		entitlement.NewPermissionEntitlement(resource, enterpriseRoleOwner,
+			entitlement.WithDisplayName(fmt.Sprintf("%s Enterprise Owner", resource.DisplayName)),
+			entitlement.WithDescription(fmt.Sprintf("Owner of the %s enterprise in GitHub", resource.DisplayName)),
+			entitlement.WithAnnotation(&v2.V1Identifier{
+				Id: fmt.Sprintf("enterprise:%s:role:%s", resource.Id.Resource, enterpriseRoleOwner),
+			}),
+			entitlement.WithGrantableTo(resourceTypeUser),
+		),
+		entitlement.NewPermissionEntitlement(resource, enterpriseRoleBillingManager,
+			entitlement.WithDisplayName(fmt.Sprintf("%s Enterprise Billing Manager", resource.DisplayName)),
+			entitlement.WithDescription(fmt.Sprintf("Billing manager of the %s enterprise in GitHub", resource.DisplayName)),
+			entitlement.WithAnnotation(&v2.V1Identifier{
+				Id: fmt.Sprintf("enterprise:%s:role:%s", resource.Id.Resource, enterpriseRoleBillingManager),
+			}),
+			entitlement.WithGrantableTo(resourceTypeUser),
+		),
+		entitlement.NewAssignmentEntitlement(resource, enterpriseRoleMember,
+			entitlement.WithDisplayName(fmt.Sprintf("%s Enterprise Member", resource.DisplayName)),
+			entitlement.WithDescription(fmt.Sprintf("Member of the %s enterprise in GitHub", resource.DisplayName)),
+			entitlement.WithAnnotation(&v2.V1Identifier{
+				Id: fmt.Sprintf("enterprise:%s:role:%s", resource.Id.Resource, enterpriseRoleMember),
+			}),
+			entitlement.WithGrantableTo(resourceTypeUser),
+		),
+	}, "", nil, nil
+}
+
+func (e *enterpriseResourceType) Grants(ctx context.Context, resource *v2.Resource, pToken *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
+	// GraphQL pages are cursors rather than page numbers.
+	bag := &pagination.Bag{}
+	err := bag.Unmarshal(pToken.Token)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	if bag.Current() == nil {
+		bag.Push(pagination.PageState{
+			ResourceTypeID: enterpriseRoleMember,
+		})
+		bag.Push(pagination.PageState{
+			ResourceTypeID: enterpriseRoleBillingManager,
+		})
+		bag.Push(pagination.PageState{
+			ResourceTypeID: enterpriseRoleOwner,
+		})
+	}
+
+	var cursor *githubv4.String
+	if bag.PageToken() != "" {
+		cursor = githubv4.NewString(githubv4.String(bag.PageToken()))
+	}
+	variables := map[string]interface{}{
+		"enterpriseSlug": githubv4.String(e.slug),
+		"pageSize":       githubv4.Int(enterprisePageSize),
+		"cursor":         cursor,
+	}
+
+	var users []enterpriseUser
+	var pageInfo graphqlPageInfo
+	var rateLimit graphqlRateLimit
+	roleName := bag.ResourceTypeID()
+
+	switch roleName {
+	case enterpriseRoleOwner:
+		q := enterpriseAdminsQuery{}
+		err = e.graphqlClient.Query(ctx, &q, variables)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("github-connector: failed to list enterprise owners: %w", err)
+		}
+		users = q.Enterprise.OwnerInfo.Admins.Nodes
+		pageInfo = q.Enterprise.OwnerInfo.Admins.PageInfo
+		rateLimit = q.RateLimit
+
+	case enterpriseRoleBillingManager:
+		q := enterpriseBillingManagersQuery{}
+		err = e.graphqlClient.Query(ctx, &q, variables)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("github-connector: failed to list enterprise billing managers: %w", err)
+		}
+		users = q.Enterprise.OwnerInfo.BillingManagers.Nodes
+		pageInfo = q.Enterprise.OwnerInfo.BillingManagers.PageInfo
+		rateLimit = q.RateLimit
+
+	case enterpriseRoleMember:
+		q := enterpriseMembersQuery{}
+		err = e.graphqlClient.Query(ctx, &q, variables)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("github-connector: failed to list enterprise members: %w", err)
+		}
+		for _, member := range q.Enterprise.Members.Nodes {
+			users = append(users, member.user())
+		}
+		pageInfo = q.Enterprise.Members.PageInfo
+		rateLimit = q.RateLimit
+
+	default:
+		return nil, "", nil, fmt.Errorf("unexpected resource type while fetching grants for enterprise")
+	}
+
+	rv := make([]*v2.Grant, 0, len(users))
+	for _, user := range users {
+		// Enterprise user accounts that were never linked to a user don't have a database ID.
+		if user.DatabaseId == 0 {
+			continue
+		}
+
+		// Users are synced from the orgs, so users who aren't in any synced org have no user resource to grant.
+		synced, err := e.orgCache.IsSyncedUser(ctx, user.DatabaseId)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		if !synced {
+			l.Debug("skipping enterprise grant of a user who isn't in a synced org",
+				zap.String("role", roleName),
+				zap.String("login", user.Login),
+			)
+			continue
+		}
+
+		principalID := &v2.ResourceId{ResourceType: resourceTypeUser.Id, Resource: strconv.FormatInt(user.DatabaseId, 10)}
+		rv = append(rv, grant.NewGrant(resource, roleName, principalID, grant.WithAnnotation(&v2.V1Identifier{
+			Id: fmt.Sprintf("enterprise-grant:%s:%d:%s", resource.Id.Resource, user.DatabaseId, roleName),
+		})))
+	}
+
+	nextCursor := ""
+	if pageInfo.HasNextPage {
+		nextCursor = string(pageInfo.EndCursor)
+	}
+	err = bag.Next(nextCursor)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	pageToken, err := bag.Marshal()
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	var annos annotations.Annotations
+	annos.WithRateLimiting(&v2.RateLimitDescription{
+		Limit:     int64(rateLimit.Limit),
+		Remaining: int64(rateLimit.Remaining),
+		ResetAt:   timestamppb.New(rateLimit.ResetAt.Time),
+	})
+
+	return rv, pageToken, annos, nil
+}
+
+// ResetOrgs clears the cached orgs of the enterprise, so they are listed again.
+func (e *enterpriseResourceType) ResetOrgs() {
+	e.orgsMtx.Lock()
+	defer e.orgsMtx.Unlock()
+
+	e.orgs = nil
+}
+
+// Orgs returns the logins of the orgs that belong to the enterprise, which are listed on first use and cached until
+// ResetOrgs is called.
+func (e *enterpriseResourceType) Orgs(ctx context.Context) (map[string]struct{}, error) {
+	e.orgsMtx.Lock()
+	defer e.orgsMtx.Unlock()
+
+	if e.orgs != nil {
+		return e.orgs, nil
+	}
+
+	orgs := make(map[string]struct{})
+	var cursor *githubv4.String
+	for {
+		q := enterpriseOrganizationsQuery{}
+		err := e.graphqlClient.Query(ctx, &q, map[string]interface{}{
+			"enterpriseSlug": githubv4.String(e.slug),
+			"pageSize":       githubv4.Int(enterprisePageSize),
+			"cursor":         cursor,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("github-connector: failed to list enterprise orgs: %w", err)
+		}
+
+		for _, org := range q.Enterprise.Organizations.Nodes {
+			orgs[org.Login] = struct{}{}
+		}
+
+		if !q.Enterprise.Organizations.PageInfo.HasNextPage {
+			break
+		}
+		cursor = githubv4.NewString(q.Enterprise.Organizations.PageInfo.EndCursor)
+	}
+
+	e.orgs = orgs
+	return orgs, nil
+}
+
+// listEnterpriseTwoFactor returns whether each enterprise member has two-factor authentication enabled, by database
+// ID. Members who only have an account on a GitHub Enterprise Server instance aren't included.
+func listEnterpriseTwoFactor(ctx context.Context, graphqlClient *githubv4.Client, slug string) (map[int64]bool, error) {
+	rv := make(map[int64]bool)
+	for _, enabled := range []bool{true, false} {
+		var cursor *githubv4.String
+		for {
+			q := enterpriseTwoFactorMembersQuery{}
+			err := graphqlClient.Query(ctx, &q, map[string]interface{}{
+				"enterpriseSlug":      githubv4.String(slug),
+				"pageSize":            githubv4.Int(enterprisePageSize),
+				"cursor":              cursor,
+				"hasTwoFactorEnabled": githubv4.Boolean(enabled),
+			})
+			if err != nil {
+				return nil, fmt.Errorf("github-connector: failed to list the two-factor authentication of enterprise members: %w", err)
+			}
+
+			for _, member := range q.Enterprise.Members.Nodes {
+				if user := member.user(); user.DatabaseId != 0 {
+					rv[user.DatabaseId] = enabled
+				}
+			}
+
+			if !q.Enterprise.Members.PageInfo.HasNextPage {
+				break
+			}
+			cursor = githubv4.NewString(q.Enterprise.Members.PageInfo.EndCursor)
+		}
+	}
+
+	return rv, nil
+}
+
+func enterpriseBuilder(graphqlClient *githubv4.Client, orgCache *orgNameCache, slug string) *enterpriseResourceType {
+	return &enterpriseResourceType{
+		resourceType:  resourceTypeEnterprise,
+		graphqlClient: graphqlClient,
+		orgCache:      orgCache,
+		slug:          slug,
+	}
+}
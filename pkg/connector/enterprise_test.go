@@ -0,0 +1,78 @@
+package connector
+
+import (
+	"context"
+	"testing"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	entitlement2 "github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/stretchr/testify/require"
+
+	"github.com/conductorone/baton-github/test"
+	"github.com/conductorone/baton-github/test/mocks"
+)
+
+func TestEnterprise(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should list the enterprise with its owners, billing managers and members", func(t *testing.T) {
+		seed := seedMockGitHub(t)
+		seed.mgh.AddBillingManager(seed.organization.GetID(), 57)
+		seed.mgh.AddMember(seed.organization.GetID(), 58)
+
+		client := enterpriseBuilder(mocks.MockGraphQL(), seed.cache, "acme-corp")
+
+		enterprises, _, _, err := client.List(ctx, nil, &pagination.Token{})
+		require.Nil(t, err)
+		require.Len(t, enterprises, 1)
+		require.Equal(t, "12", enterprises[0].Id.Resource)
+		require.Equal(t, "Acme Corp", enterprises[0].DisplayName)
+
+		entitlements, _, _, err := client.Entitlements(ctx, enterprises[0], &pagination.Token{})
+		require.Nil(t, err)
+		require.Len(t, entitlements, 3)
+
+		grants := test.ExhaustPagination(t, func(pToken *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+			return client.Grants(ctx, enterprises[0], pToken)
+		})
+		require.Len(t, grants, 4)
+
+		principals := make(map[string][]string)
+		for _, grant := range grants {
+			principals[grant.Entitlement.Id] = append(principals[grant.Entitlement.Id], grant.Principal.Id.Resource)
+		}
+		require.Equal(t, []string{"56"}, principals[entitlement2.NewEntitlementID(enterprises[0], enterpriseRoleOwner)])
+		require.Equal(t, []string{"57"}, principals[entitlement2.NewEntitlementID(enterprises[0], enterpriseRoleBillingManager)])
+		require.ElementsMatch(t, []string{"56", "58"}, principals[entitlement2.NewEntitlementID(enterprises[0], enterpriseRoleMember)])
+
+		orgs, err := client.Orgs(ctx)
+		require.Nil(t, err)
+		require.Contains(t, orgs, "acme")
+	})
+
+	t.Run("should skip the grants of users who aren't in a synced org", func(t *testing.T) {
+		// The enterprise owner is only a member of an org that isn't synced.
+		seed := seedMockGitHub(t)
+		seed.mgh.SetViewerOrgRole(seed.organization.GetID(), "member")
+		otherOrganization := seed.mgh.AddOrganization(13, "organization-13")
+		seed.mgh.AddBillingManager(otherOrganization.GetID(), 57)
+		seed.mgh.AddMember(otherOrganization.GetID(), 58)
+
+		client := enterpriseBuilder(mocks.MockGraphQL(), seed.cache, "acme-corp")
+
+		enterprises, _, _, err := client.List(ctx, nil, &pagination.Token{})
+		require.Nil(t, err)
+		require.Len(t, enterprises, 1)
+
+		grants := test.ExhaustPagination(t, func(pToken *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+			return client.Grants(ctx, enterprises[0], pToken)
+		})
+		require.Len(t, grants, 2)
+
+		for _, grant := range grants {
+			require.NotEqual(t, "56", grant.Principal.Id.Resource)
+		}
+	})
+}
@@ -0,0 +1,472 @@
+package connector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/conductorone/baton-sdk/pkg/types/grant"
+	"github.com/google/go-github/v63/github"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const auditLogPageSize = 100
+
+// auditLogActions are the audit log actions that change access, which are the only entries mapped to events.
+var auditLogActions = map[string]struct{}{
+	"org.add_member":     {},
+	"org.remove_member":  {},
+	"org.update_member":  {},
+	"team.add_member":    {},
+	"team.remove_member": {},
+	"repo.add_member":    {},
+	"repo.update_member": {},
+	"repo.remove_member": {},
+}
+
+// auditLogSource is an org or enterprise audit log that events are read from.
+type auditLogSource struct {
+	key        string
+	org        string
+	enterprise string
+	client     *github.Client
+}
+
+// getAuditLog reads a page of the audit log.
+func (s *auditLogSource) getAuditLog(ctx context.Context, opts *github.GetAuditLogOptions) ([]*github.AuditEntry, *github.Response, error) {
+	if s.enterprise != "" {
+		return s.client.Enterprise.GetAuditLog(ctx, s.enterprise, opts)
+	}
+
+	return s.client.Organizations.GetAuditLog(ctx, s.org, opts)
+}
+
+// auditLogPosition is how far an audit log has been read.
+type auditLogPosition struct {
+	// Since is the start of the pass over the audit log that is in progress. It must not change while paging.
+	Since time.Time `json:"since"`
+	After string    `json:"after,omitempty"`
+	// LatestAt is the time of the newest event read, and the start of the next pass.
+	LatestAt time.Time `json:"latest_at"`
+	// LatestIDs are the events at LatestAt. The created qualifier is inclusive, so they are skipped on the next pass.
+	LatestIDs []string `json:"latest_ids,omitempty"`
+}
+
+// eventFeedCursor is persisted in the stream token so that incremental pulls resume where they left off.
+type eventFeedCursor struct {
+	Source    int                          `json:"source"`
+	Positions map[string]*auditLogPosition `json:"positions"`
+}
+
+// ListEvents returns access changes from the audit logs of the synced orgs, and the enterprise audit log when
+// configured. The audit logs are read one after the other.
+func (gh *GitHub) ListEvents(
+	ctx context.Context,
+	earliestEvent *timestamppb.Timestamp,
+	pToken *pagination.StreamToken,
+) ([]*v2.Event, *pagination.StreamState, annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
+	cursor := &eventFeedCursor{}
+	if pToken.Cursor != "" {
+		err := json.Unmarshal([]byte(pToken.Cursor), cursor)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("github-connector: failed to parse event feed cursor: %w", err)
+		}
+	}
+	if cursor.Positions == nil {
+		cursor.Positions = make(map[string]*auditLogPosition)
+	}
+
+	sources, err := gh.auditLogSources(ctx)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if cursor.Source >= len(sources) {
+		cursor.Source = 0
+	}
+	if len(sources) == 0 {
+		return nil, &pagination.StreamState{Cursor: pToken.Cursor}, nil, nil
+	}
+
+	source := sources[cursor.Source]
+	position, ok := cursor.Positions[source.key]
+	if !ok {
+		position = &auditLogPosition{}
+		cursor.Positions[source.key] = position
+	}
+
+	// Start a new pass from the newest event read in the last one.
+	if position.After == "" {
+		position.Since = position.LatestAt
+		if earliestEvent != nil && earliestEvent.AsTime().After(position.Since) {
+			position.Since = earliestEvent.AsTime()
+		}
+	}
+
+	pageSize := pToken.Size
+	if pageSize == 0 || pageSize > auditLogPageSize {
+		pageSize = auditLogPageSize
+	}
+	opts := &github.GetAuditLogOptions{
+		Order: github.String("asc"),
+		ListCursorOptions: github.ListCursorOptions{
+			PerPage: pageSize,
+			After:   position.After,
+		},
+	}
+	if !position.Since.IsZero() {
+		opts.Phrase = github.String(fmt.Sprintf("created:>=%s", position.Since.UTC().Format(time.RFC3339)))
+	}
+
+	entries, resp, err := source.getAuditLog(ctx, opts)
+	if err != nil {
+		// The audit log API is only available on GitHub Enterprise Cloud, and needs the audit log scope.
+		if resp == nil || (resp.StatusCode != http.StatusNotFound && resp.StatusCode != http.StatusForbidden) {
+			return nil, nil, nil, fmt.Errorf("github-connector: failed to get audit log for %s: %w", source.key, err)
+		}
+		l.Warn("github-connector: audit log is not available, skipping", zap.String("source", source.key), zap.Error(err))
+		resp = &github.Response{}
+	}
+
+	var rv []*v2.Event
+	lookup := gh.auditLogLookup(source)
+	for _, entry := range entries {
+		if slices.Contains(position.LatestIDs, entry.GetDocumentID()) {
+			continue
+		}
+
+		occurredAt := auditEntryTime(entry)
+		switch {
+		case occurredAt.After(position.LatestAt):
+			position.LatestAt = occurredAt
+			position.LatestIDs = []string{entry.GetDocumentID()}
+		case occurredAt.Equal(position.LatestAt):
+			position.LatestIDs = append(position.LatestIDs, entry.GetDocumentID())
+		}
+
+		if _, ok := auditLogActions[entry.GetAction()]; !ok {
+			continue
+		}
+		event, err := lookup.auditEntryEvent(ctx, entry)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if event == nil {
+			continue
+		}
+		rv = append(rv, event)
+	}
+
+	position.After = resp.After
+	hasMore := true
+	if position.After == "" {
+		cursor.Source++
+		if cursor.Source >= len(sources) {
+			cursor.Source = 0
+			hasMore = false
+		}
+	}
+
+	nextCursor, err := json.Marshal(cursor)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	var annos annotations.Annotations
+	if resp.Response != nil {
+		_, annos, err = parseResp(resp)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	return rv, &pagination.StreamState{Cursor: string(nextCursor), HasMore: hasMore}, annos, nil
+}
+
+// auditLogLookup returns the lookup of the audit log, which is kept across pages so that the teams and repositories in
+// its entries are only looked up once.
+func (gh *GitHub) auditLogLookup(source *auditLogSource) *auditLogLookup {
+	gh.auditLogLookupsMtx.Lock()
+	defer gh.auditLogLookupsMtx.Unlock()
+
+	if gh.auditLogLookups == nil {
+		gh.auditLogLookups = make(map[string]*auditLogLookup)
+	}
+	lookup, ok := gh.auditLogLookups[source.key]
+	if !ok {
+		lookup = newAuditLogLookup(source.client, gh.repoFilter)
+		gh.auditLogLookups[source.key] = lookup
+	}
+
+	return lookup
+}
+
+// auditLogSources returns the audit logs to read events from, in a stable order.
+func (gh *GitHub) auditLogSources(ctx context.Context) ([]*auditLogSource, error) {
+	var rv []*auditLogSource
+
+	if gh.appInstallations != nil {
+		for _, installation := range gh.appInstallations {
+			account := installation.GetAccount()
+			client, err := gh.orgCache.GetClient(&v2.ResourceId{
+				ResourceType: resourceTypeOrg.Id,
+				Resource:     strconv.FormatInt(account.GetID(), 10),
+			})
+			if err != nil {
+				return nil, err
+			}
+			rv = append(rv, &auditLogSource{
+				key:    "org:" + account.GetLogin(),
+				org:    account.GetLogin(),
+				client: client,
+			})
+		}
+		return rv, nil
+	}
+
+	orgs := gh.orgs
+	if len(orgs) == 0 {
+		opts := &github.ListOptions{PerPage: 100}
+		for {
+			page, resp, err := gh.client.Organizations.List(ctx, "", opts)
+			if err != nil {
+				return nil, fmt.Errorf("github-connector: failed to list orgs: %w", err)
+			}
+			for _, org := range page {
+				orgs = append(orgs, org.GetLogin())
+			}
+
+			if resp.NextPage == 0 {
+				break
+			}
+			opts.Page = resp.NextPage
+		}
+	}
+
+	for _, org := range orgs {
+		rv = append(rv, &auditLogSource{key: "org:" + org, org: org, client: gh.client})
+	}
+
+	// Enterprise audit logs can't be read with app installation tokens.
+	if gh.enterprise != "" {
+		rv = append(rv, &auditLogSource{key: "enterprise:" + gh.enterprise, enterprise: gh.enterprise, client: gh.client})
+	}
+
+	return rv, nil
+}
+
+func auditEntryTime(entry *github.AuditEntry) time.Time {
+	if entry.Timestamp != nil {
+		return entry.GetTimestamp().Time
+	}
+	return entry.GetCreatedAt().Time
+}
+
+// auditEntryField returns a string field of an audit log entry that go-github doesn't define.
+func auditEntryField(entry *github.AuditEntry, name string) string {
+	if v, ok := entry.AdditionalFields[name].(string); ok {
+		return v
+	}
+	if v, ok := entry.Data[name].(string); ok {
+		return v
+	}
+	return ""
+}
+
+// auditLogLookup resolves the team and repository names in audit log entries to their IDs. Teams and repositories that
+// don't exist aren't cached, since they can be created later with the same name.
+type auditLogLookup struct {
+	client     *github.Client
+	repoFilter repositoryFilter
+
+	// mtx guards teams and repos.
+	mtx   sync.Mutex
+	teams map[string]int64
+	repos map[string]int64
+}
+
+func newAuditLogLookup(client *github.Client, repoFilter repositoryFilter) *auditLogLookup {
+	return &auditLogLookup{
+		client:     client,
+		repoFilter: repoFilter,
+		teams:      make(map[string]int64),
+		repos:      make(map[string]int64),
+	}
+}
+
+// teamID returns the ID of a team given as "org/team-slug", or 0 if the team no longer exists.
+func (a *auditLogLookup) teamID(ctx context.Context, name string) (int64, error) {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	if id, ok := a.teams[name]; ok {
+		return id, nil
+	}
+
+	org, slug, ok := strings.Cut(name, "/")
+	if !ok {
+		return 0, nil
+	}
+
+	team, resp, err := a.client.Teams.GetTeamBySlug(ctx, org, slug)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("github-connector: failed to get team %s: %w", name, err)
+	}
+
+	a.teams[name] = team.GetID()
+	return team.GetID(), nil
+}
+
+// repoID returns the ID of a repository given as "org/repo", or 0 if the repository no longer exists or isn't synced.
+func (a *auditLogLookup) repoID(ctx context.Context, name string) (int64, error) {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	if id, ok := a.repos[name]; ok {
+		return id, nil
+	}
+
+	owner, repoName, ok := strings.Cut(name, "/")
+	if !ok {
+		return 0, nil
+	}
+
+	repo, resp, err := a.client.Repositories.Get(ctx, owner, repoName)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("github-connector: failed to get repository %s: %w", name, err)
+	}
+
+	if !a.repoFilter.matches(repo) {
+		a.repos[name] = 0
+		return 0, nil
+	}
+
+	a.repos[name] = repo.GetID()
+	return repo.GetID(), nil
+}
+
+// auditEntryEvent maps an audit log entry of one of the auditLogActions to a grant or revoke event. It returns nil for
+// entries that can't be mapped.
+func (a *auditLogLookup) auditEntryEvent(ctx context.Context, entry *github.AuditEntry) (*v2.Event, error) {
+	event := &v2.Event{
+		Id:         entry.GetDocumentID(),
+		OccurredAt: timestamppb.New(auditEntryTime(entry)),
+	}
+
+	var principal *v2.Resource
+	if entry.UserID != nil {
+		principal = &v2.Resource{Id: &v2.ResourceId{
+			ResourceType: resourceTypeUser.Id,
+			Resource:     strconv.FormatInt(entry.GetUserID(), 10),
+		}}
+	}
+	org := &v2.Resource{Id: &v2.ResourceId{
+		ResourceType: resourceTypeOrg.Id,
+		Resource:     strconv.FormatInt(entry.GetOrgID(), 10),
+	}}
+
+	switch entry.GetAction() {
+	case "org.add_member":
+		return grantEvent(event, org, orgRoleMember, principal), nil
+
+	case "org.remove_member":
+		return revokeEvent(event, org, orgRoleMember, principal), nil
+
+	case "org.update_member":
+		if auditEntryField(entry, "permission") == orgRoleAdmin {
+			return grantEvent(event, org, orgRoleAdmin, principal), nil
+		}
+		return revokeEvent(event, org, orgRoleAdmin, principal), nil
+
+	case "team.add_member", "team.remove_member":
+		teamID, err := a.teamID(ctx, auditEntryField(entry, "team"))
+		if err != nil || teamID == 0 {
+			return nil, err
+		}
+		team := &v2.Resource{
+			Id:               &v2.ResourceId{ResourceType: resourceTypeTeam.Id, Resource: strconv.FormatInt(teamID, 10)},
+			ParentResourceId: org.Id,
+		}
+		if entry.GetAction() == "team.add_member" {
+			return grantEvent(event, team, teamRoleMember, principal), nil
+		}
+		return revokeEvent(event, team, teamRoleMember, principal), nil
+	}
+
+	repoName := auditEntryField(entry, "repo")
+	if repoName == "" {
+		return nil, nil
+	}
+	repoID, err := a.repoID(ctx, repoName)
+	if err != nil || repoID == 0 {
+		return nil, err
+	}
+	repo := &v2.Resource{
+		Id:               &v2.ResourceId{ResourceType: resourceTypeRepository.Id, Resource: strconv.FormatInt(repoID, 10)},
+		ParentResourceId: org.Id,
+	}
+
+	switch entry.GetAction() {
+	case "repo.add_member", "repo.update_member":
+		permission, ok := repoPermissionFromRoleName(auditEntryField(entry, "permission"))
+		if !ok {
+			return nil, nil
+		}
+		return grantEvent(event, repo, permission, principal), nil
+
+	case "repo.remove_member":
+		permission, ok := repoPermissionFromRoleName(auditEntryField(entry, "old_permission"))
+		if !ok {
+			permission, ok = repoPermissionFromRoleName(auditEntryField(entry, "permission"))
+		}
+		if !ok {
+			return nil, nil
+		}
+		return revokeEvent(event, repo, permission, principal), nil
+	}
+
+	return nil, nil
+}
+
+func grantEvent(event *v2.Event, resource *v2.Resource, entitlementName string, principal *v2.Resource) *v2.Event {
+	if principal == nil {
+		return nil
+	}
+	event.Event = &v2.Event_GrantEvent{GrantEvent: &v2.GrantEvent{
+		Grant: grant.NewGrant(resource, entitlementName, principal.Id),
+	}}
+	return event
+}
+
+func revokeEvent(event *v2.Event, resource *v2.Resource, entitlementName string, principal *v2.Resource) *v2.Event {
+	if principal == nil {
+		return nil
+	}
+	event.Event = &v2.Event_RevokeEvent{RevokeEvent: &v2.RevokeEvent{
+		Entitlement: &v2.Entitlement{
+			Id:       entitlement.NewEntitlementID(resource, entitlementName),
+			Resource: resource,
+		},
+		Principal: principal,
+	}}
+	return event
+}
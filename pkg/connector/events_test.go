@@ -0,0 +1,62 @@
+package connector
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListEvents(t *testing.T) {
+	ctx := context.Background()
+
+	seed := seedMockGitHub(t)
+	occurredAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	seed.mgh.AddAuditLogEntry(seed.organization.GetID(), "org.add_member", seed.user.GetID(), occurredAt, nil)
+	seed.mgh.AddAuditLogEntry(seed.organization.GetID(), "org.update_member", seed.user.GetID(), occurredAt, map[string]interface{}{
+		"permission": "admin",
+	})
+	seed.mgh.AddAuditLogEntry(seed.organization.GetID(), "org.oauth_app_access_approved", seed.user.GetID(), occurredAt, nil)
+	// Entries that don't change access aren't mapped, even when they act on a repository.
+	seed.mgh.AddAuditLogEntry(seed.organization.GetID(), "git.clone", seed.user.GetID(), occurredAt, map[string]interface{}{
+		"repo": seed.organization.GetLogin() + "/" + seed.repository.GetName(),
+	})
+
+	gh := &GitHub{
+		orgs:     []string{seed.organization.GetLogin()},
+		client:   seed.client,
+		orgCache: seed.cache,
+	}
+
+	organization, _ := organizationResource(ctx, seed.organization, nil)
+
+	events, state, _, err := gh.ListEvents(ctx, nil, &pagination.StreamToken{})
+	require.Nil(t, err)
+	require.False(t, state.HasMore)
+	require.Len(t, events, 2)
+
+	grants := make([]*v2.Grant, 0)
+	for _, event := range events {
+		grants = append(grants, event.GetGrantEvent().GetGrant())
+	}
+	require.Equal(t, entitlement.NewEntitlementID(organization, orgRoleMember), grants[0].Entitlement.Id)
+	require.Equal(t, entitlement.NewEntitlementID(organization, orgRoleAdmin), grants[1].Entitlement.Id)
+	require.Equal(t, seed.user.GetLogin(), grants[0].Principal.Id.Resource)
+
+	t.Run("should resume from the stream cursor", func(t *testing.T) {
+		seed.mgh.AddAuditLogEntry(seed.organization.GetID(), "org.remove_member", seed.user.GetID(), occurredAt.Add(time.Hour), nil)
+
+		events, state, _, err := gh.ListEvents(ctx, nil, &pagination.StreamToken{Cursor: state.Cursor})
+		require.Nil(t, err)
+		require.False(t, state.HasMore)
+		require.Len(t, events, 1)
+
+		revoke := events[0].GetRevokeEvent()
+		require.NotNil(t, revoke)
+		require.Equal(t, entitlement.NewEntitlementID(organization, orgRoleMember), revoke.Entitlement.Id)
+	})
+}
@@ -3,6 +3,8 @@ package connector
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
@@ -25,8 +27,69 @@ func titleCase(s string) string {
 
 type orgNameCache struct {
 	sync.RWMutex
+	// c is the default client for orgs without a client of their own. It's nil when authenticating as a GitHub App,
+	// since the app's own token can't read org data.
 	c        *github.Client
 	orgNames map[string]string
+	// orgClients holds the clients authenticated with each org's GitHub App installation, keyed by org ID.
+	orgClients map[string]*orgClient
+
+	// orgs limits the synced orgs to these logins, if any are set.
+	orgs []string
+	// syncedOrgsMtx guards syncedOrgs, the orgs the connector syncs, which are listed once.
+	syncedOrgsMtx sync.Mutex
+	syncedOrgs    []*v2.ResourceId
+
+	// orgUsersMtx guards orgUsers, the outside collaborators and billing managers of each org, keyed by org ID.
+	orgUsersMtx sync.Mutex
+	orgUsers    map[string]*orgUsers
+}
+
+type orgClient struct {
+	client        *github.Client
+	graphqlClient *githubv4.Client
+}
+
+// SetOrgClient registers the clients to use for requests scoped to the given org.
+func (o *orgNameCache) SetOrgClient(orgID int64, orgName string, client *github.Client, graphqlClient *githubv4.Client) {
+	o.Lock()
+	defer o.Unlock()
+
+	id := strconv.FormatInt(orgID, 10)
+	o.orgNames[id] = orgName
+	o.orgClients[id] = &orgClient{
+		client:        client,
+		graphqlClient: graphqlClient,
+	}
+}
+
+// GetClient returns the client to use for requests scoped to the org, falling back to the default client. It returns
+// an error for orgs without a GitHub App installation when authenticating as a GitHub App.
+func (o *orgNameCache) GetClient(orgID *v2.ResourceId) (*github.Client, error) {
+	o.RLock()
+	defer o.RUnlock()
+
+	if c, ok := o.orgClients[orgID.GetResource()]; ok {
+		return c.client, nil
+	}
+
+	if o.c == nil {
+		return nil, fmt.Errorf("github-connector: the GitHub App isn't installed on org %s", orgID.GetResource())
+	}
+
+	return o.c, nil
+}
+
+// GetGraphqlClient returns the GraphQL client registered for the org, or nil if there isn't one.
+func (o *orgNameCache) GetGraphqlClient(orgID *v2.ResourceId) *githubv4.Client {
+	o.RLock()
+	defer o.RUnlock()
+
+	if c, ok := o.orgClients[orgID.GetResource()]; ok {
+		return c.graphqlClient
+	}
+
+	return nil
 }
 
 func (o *orgNameCache) GetOrgName(ctx context.Context, orgID *v2.ResourceId) (string, error) {
@@ -44,6 +107,10 @@ func (o *orgNameCache) GetOrgName(ctx context.Context, orgID *v2.ResourceId) (st
 		return orgName, nil
 	}
 
+	if o.c == nil {
+		return "", fmt.Errorf("github-connector: the GitHub App isn't installed on org %s", orgID.Resource)
+	}
+
 	oID, err := strconv.ParseInt(orgID.Resource, 10, 64)
 	if err != nil {
 		return "", err
@@ -59,10 +126,106 @@ func (o *orgNameCache) GetOrgName(ctx context.Context, orgID *v2.ResourceId) (st
 	return org.GetLogin(), nil
 }
 
-func newOrgNameCache(c *github.Client) *orgNameCache {
+// GetOrgID returns the ID of the org with the given login, out of the orgs the connector has access to.
+func (o *orgNameCache) GetOrgID(ctx context.Context, orgName string) (*v2.ResourceId, error) {
+	findOrg := func() *v2.ResourceId {
+		o.RLock()
+		defer o.RUnlock()
+
+		for id, name := range o.orgNames {
+			if strings.EqualFold(name, orgName) {
+				return &v2.ResourceId{ResourceType: resourceTypeOrg.Id, Resource: id}
+			}
+		}
+		return nil
+	}
+
+	if orgID := findOrg(); orgID != nil {
+		return orgID, nil
+	}
+
+	// Listing the orgs caches their names.
+	_, err := o.OrgIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if orgID := findOrg(); orgID != nil {
+		return orgID, nil
+	}
+
+	return nil, fmt.Errorf("github-connector: org %s isn't accessible with the configured credentials", orgName)
+}
+
+// OrgIDs returns the orgs the connector syncs, the same orgs the org resource type lists. These are the app
+// installation orgs when authenticating as a GitHub App, and otherwise the orgs the authenticated user is an admin of,
+// limited to the configured orgs. They're listed once and cached.
+func (o *orgNameCache) OrgIDs(ctx context.Context) ([]*v2.ResourceId, error) {
+	o.syncedOrgsMtx.Lock()
+	defer o.syncedOrgsMtx.Unlock()
+
+	if o.syncedOrgs != nil {
+		return slices.Clone(o.syncedOrgs), nil
+	}
+
+	rv := make([]*v2.ResourceId, 0)
+	o.RLock()
+	for id := range o.orgClients {
+		rv = append(rv, &v2.ResourceId{ResourceType: resourceTypeOrg.Id, Resource: id})
+	}
+	o.RUnlock()
+	if len(rv) > 0 || o.c == nil {
+		o.syncedOrgs = rv
+		return slices.Clone(rv), nil
+	}
+
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		orgs, resp, err := o.c.Organizations.List(ctx, "", opts)
+		if err != nil {
+			return nil, fmt.Errorf("github-connector: failed to list orgs: %w", err)
+		}
+
+		for _, org := range orgs {
+			if len(o.orgs) > 0 && !slices.Contains(o.orgs, org.GetLogin()) {
+				continue
+			}
+
+			membership, resp, err := o.c.Organizations.GetOrgMembership(ctx, "", org.GetLogin())
+			if err != nil {
+				if resp != nil && resp.StatusCode == http.StatusForbidden {
+					continue
+				}
+				return nil, fmt.Errorf("github-connector: failed to get org membership in %s: %w", org.GetLogin(), err)
+			}
+			if strings.ToLower(membership.GetRole()) != orgRoleAdmin {
+				continue
+			}
+
+			id := strconv.FormatInt(org.GetID(), 10)
+			o.Lock()
+			o.orgNames[id] = org.GetLogin()
+			o.Unlock()
+			rv = append(rv, &v2.ResourceId{ResourceType: resourceTypeOrg.Id, Resource: id})
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	o.syncedOrgs = rv
+
+	return slices.Clone(rv), nil
+}
+
+// newOrgNameCache returns an org cache with the default client, syncing only the given orgs if any are set.
+func newOrgNameCache(c *github.Client, orgs ...string) *orgNameCache {
 	return &orgNameCache{
-		c:        c,
-		orgNames: make(map[string]string),
+		c:          c,
+		orgs:       orgs,
+		orgNames:   make(map[string]string),
+		orgClients: make(map[string]*orgClient),
+		orgUsers:   make(map[string]*orgUsers),
 	}
 }
 
@@ -176,33 +339,37 @@ func extractRateLimitData(response *github.Response) (*v2.RateLimitDescription,
 	}, nil
 }
 
-type listUsersQuery struct {
+type listExternalIdentitiesQuery struct {
 	Organization struct {
 		SamlIdentityProvider struct {
 			SsoUrl             githubv4.String
 			ExternalIdentities struct {
 				Edges []struct {
 					Node struct {
+						Guid         string
 						SamlIdentity struct {
 							NameId string
 							Emails []struct {
 								Value string
 							}
 						}
+						ScimIdentity struct {
+							Username string
+							Emails   []struct {
+								Value   string
+								Primary bool
+							}
+						}
 						User struct {
 							Login string
 						}
 					}
 				}
-			} `graphql:"externalIdentities(first: 1, login: $userName)"`
+				PageInfo graphqlPageInfo
+			} `graphql:"externalIdentities(first: $pageSize, after: $cursor)"`
 		}
 	} `graphql:"organization(login: $orgLoginName)"`
-	RateLimit struct {
-		Limit     int
-		Cost      int
-		Remaining int
-		ResetAt   githubv4.DateTime
-	}
+	RateLimit graphqlRateLimit
 }
 
 type hasSAMLQuery struct {
@@ -212,3 +379,13 @@ type hasSAMLQuery struct {
 		}
 	} `graphql:"organization(login: $orgLoginName)"`
 }
+
+// IsSyncedOrg returns true if the org is one of the orgs the connector syncs.
+func (o *orgNameCache) IsSyncedOrg(ctx context.Context, orgID *v2.ResourceId) (bool, error) {
+	orgIDs, err := o.OrgIDs(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	return slices.ContainsFunc(orgIDs, func(id *v2.ResourceId) bool { return id.GetResource() == orgID.GetResource() }), nil
+}
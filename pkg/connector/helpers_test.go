@@ -0,0 +1,39 @@
+package connector
+
+import (
+	"testing"
+
+	"github.com/google/go-github/v63/github"
+	"github.com/stretchr/testify/require"
+
+	"github.com/conductorone/baton-github/test/mocks"
+)
+
+// mockGitHubSeed is a mock GitHub seeded with an org, repository, team and user, and the client and org cache for it.
+type mockGitHubSeed struct {
+	mgh          *mocks.MockGitHub
+	organization *github.Organization
+	repository   *github.Repository
+	team         *github.Team
+	user         *github.User
+	client       *github.Client
+	cache        *orgNameCache
+}
+
+func seedMockGitHub(t *testing.T) *mockGitHubSeed {
+	mgh := mocks.NewMockGitHub()
+
+	githubOrganization, githubRepository, githubTeam, githubUser, err := mgh.Seed()
+	require.Nil(t, err)
+
+	githubClient := github.NewClient(mgh.Server())
+	return &mockGitHubSeed{
+		mgh:          mgh,
+		organization: githubOrganization,
+		repository:   githubRepository,
+		team:         githubTeam,
+		user:         githubUser,
+		client:       githubClient,
+		cache:        newOrgNameCache(githubClient),
+	}
+}
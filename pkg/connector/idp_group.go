@@ -0,0 +1,212 @@
+package connector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	rType "github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/google/go-github/v63/github"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+)
+
+// errTeamSyncUnavailable is returned when the IdP groups of an org can't be read, either because team synchronization
+// isn't enabled for the org or because the credentials can't read it.
+var errTeamSyncUnavailable = errors.New("github-connector: team synchronization is unavailable")
+
+// idpGroupResource creates a new connector resource for a group of the org's identity provider, which teams are
+// synchronized with.
+func idpGroupResource(group *github.IDPGroup, orgID *v2.ResourceId) (*v2.Resource, error) {
+	profile := map[string]interface{}{
+		"group_id":          group.GetGroupID(),
+		"group_name":        group.GetGroupName(),
+		"group_description": group.GetGroupDescription(),
+	}
+
+	return rType.NewGroupResource(
+		group.GetGroupName(),
+		resourceTypeIDPGroup,
+		group.GetGroupID(),
+		[]rType.GroupTraitOption{rType.WithGroupProfile(profile)},
+		rType.WithParentResourceID(orgID),
+		rType.WithDescription(group.GetGroupDescription()),
+	)
+}
+
+// idpGroupFromResource returns the IdP group of a connector resource.
+func idpGroupFromResource(resource *v2.Resource) *github.IDPGroup {
+	group := &github.IDPGroup{
+		GroupID:          github.String(resource.Id.Resource),
+		GroupName:        github.String(resource.DisplayName),
+		GroupDescription: github.String(resource.Description),
+	}
+
+	groupTrait, err := rType.GetGroupTrait(resource)
+	if err == nil {
+		if name, ok := rType.GetProfileStringValue(groupTrait.Profile, "group_name"); ok {
+			group.GroupName = github.String(name)
+		}
+		if description, ok := rType.GetProfileStringValue(groupTrait.Profile, "group_description"); ok {
+			group.GroupDescription = github.String(description)
+		}
+	}
+
+	return group
+}
+
+// listTeamIDPGroups returns the IdP groups that the team's membership is synchronized with.
+func listTeamIDPGroups(ctx context.Context, client *github.Client, orgName string, teamSlug string) ([]*github.IDPGroup, error) {
+	groups, resp, err := client.Teams.ListIDPGroupsForTeamBySlug(ctx, orgName, teamSlug)
+	if err != nil {
+		if resp != nil && (resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusForbidden) {
+			return nil, errTeamSyncUnavailable
+		}
+		return nil, fmt.Errorf("github-connector: failed to list IdP groups of team %s: %w", teamSlug, err)
+	}
+
+	return groups.Groups, nil
+}
+
+// withIDPGroups records the IdP groups that the team's membership is synchronized with in the team's profile.
+func withIDPGroups(groups []*github.IDPGroup) teamResourceOption {
+	return func(profile map[string]interface{}) {
+		ids := make([]string, 0, len(groups))
+		names := make([]string, 0, len(groups))
+		for _, group := range groups {
+			ids = append(ids, group.GetGroupID())
+			names = append(names, group.GetGroupName())
+		}
+
+		profile["team_sync"] = len(groups) > 0
+		profile["idp_group_ids"] = strings.Join(ids, ",")
+		profile["idp_group_names"] = strings.Join(names, ",")
+	}
+}
+
+// idpGroupsFromTeamProfile returns the IdP groups recorded in the team's profile by withIDPGroups, so they aren't
+// listed again for every team.
+func idpGroupsFromTeamProfile(team *v2.Resource) ([]*github.IDPGroup, error) {
+	groupTrait, err := rType.GetGroupTrait(team)
+	if err != nil {
+		return nil, err
+	}
+
+	ids, _ := rType.GetProfileStringValue(groupTrait.Profile, "idp_group_ids")
+	if ids == "" {
+		return nil, nil
+	}
+	groupIDs := strings.Split(ids, ",")
+
+	// Group names that have commas can't be split apart, so the names are only used when they line up with the IDs.
+	names, _ := rType.GetProfileStringValue(groupTrait.Profile, "idp_group_names")
+	groupNames := strings.Split(names, ",")
+	if len(groupNames) != len(groupIDs) {
+		groupNames = groupIDs
+	}
+
+	rv := make([]*github.IDPGroup, 0, len(groupIDs))
+	for i, id := range groupIDs {
+		rv = append(rv, &github.IDPGroup{
+			GroupID:   github.String(id),
+			GroupName: github.String(groupNames[i]),
+		})
+	}
+
+	return rv, nil
+}
+
+type idpGroupResourceType struct {
+	resourceType *v2.ResourceType
+	client       *github.Client
+	orgCache     *orgNameCache
+}
+
+func (o *idpGroupResourceType) ResourceType(_ context.Context) *v2.ResourceType {
+	return o.resourceType
+}
+
+// List lists the IdP groups of the org. The groups are only available when team synchronization is enabled for the
+// org.
+func (o *idpGroupResourceType) List(ctx context.Context, parentID *v2.ResourceId, pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	if parentID == nil || parentID.ResourceType != resourceTypeOrg.Id {
+		return nil, "", nil, nil
+	}
+
+	bag := &pagination.Bag{}
+	err := bag.Unmarshal(pToken.Token)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	if bag.Current() == nil {
+		bag.Push(pagination.PageState{ResourceTypeID: resourceTypeIDPGroup.Id})
+	}
+
+	orgName, err := o.orgCache.GetOrgName(ctx, parentID)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	// IdP groups are paginated with an opaque page token.
+	client, err := o.orgCache.GetClient(parentID)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	groups, resp, err := client.Teams.ListIDPGroupsInOrganization(ctx, orgName, &github.ListCursorOptions{
+		Page:    bag.PageToken(),
+		PerPage: pToken.Size,
+	})
+	if err != nil {
+		if resp != nil && (resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusForbidden) {
+			ctxzap.Extract(ctx).Warn("can't list IdP groups, team synchronization is unavailable", zap.String("org", orgName), zap.Error(err))
+			return nil, "", nil, nil
+		}
+		return nil, "", nil, fmt.Errorf("github-connector: failed to list IdP groups: %w", err)
+	}
+
+	_, reqAnnos, err := parseResp(resp)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	nextPage := resp.NextPageToken
+	if nextPage == "" {
+		nextPage = fmtGitHubPageToken(resp.NextPage)
+	}
+	pageToken, err := bag.NextToken(nextPage)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	rv := make([]*v2.Resource, 0, len(groups.Groups))
+	for _, group := range groups.Groups {
+		gr, err := idpGroupResource(group, parentID)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		rv = append(rv, gr)
+	}
+
+	return rv, pageToken, reqAnnos, nil
+}
+
+func (o *idpGroupResourceType) Entitlements(_ context.Context, _ *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	return nil, "", nil, nil
+}
+
+func (o *idpGroupResourceType) Grants(_ context.Context, _ *v2.Resource, _ *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	return nil, "", nil, nil
+}
+
+func idpGroupBuilder(client *github.Client, orgCache *orgNameCache) *idpGroupResourceType {
+	return &idpGroupResourceType{
+		resourceType: resourceTypeIDPGroup,
+		client:       client,
+		orgCache:     orgCache,
+	}
+}
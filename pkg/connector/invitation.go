@@ -0,0 +1,209 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/google/go-github/v63/github"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const invitationStatePending = "pending"
+
+// invitationResource creates a new connector resource for a pending GitHub organization invitation. Invitees don't
+// need a GitHub account, in which case the invitation only has an email.
+func invitationResource(invitation *github.Invitation, orgID *v2.ResourceId) (*v2.Resource, error) {
+	displayName := invitation.GetLogin()
+	if displayName == "" {
+		displayName = invitation.GetEmail()
+	}
+
+	profile := map[string]interface{}{
+		"login":   invitation.GetLogin(),
+		"email":   invitation.GetEmail(),
+		"role":    invitation.GetRole(),
+		"state":   invitationStatePending,
+		"inviter": invitation.GetInviter().GetLogin(),
+	}
+	if invitation.CreatedAt != nil {
+		profile["created_at"] = invitation.GetCreatedAt().String()
+	}
+
+	userTrait := []resource.UserTraitOption{
+		resource.WithUserProfile(profile),
+	}
+	if invitation.GetEmail() != "" {
+		userTrait = append(userTrait, resource.WithEmail(invitation.GetEmail(), true))
+	}
+	if invitation.GetLogin() != "" {
+		userTrait = append(userTrait, resource.WithUserLogin(invitation.GetLogin()))
+	}
+
+	return resource.NewUserResource(
+		displayName,
+		resourceTypeInvitation,
+		invitation.GetID(),
+		userTrait,
+		resource.WithAnnotation(
+			&v2.V1Identifier{Id: fmt.Sprintf("invitation:%d", invitation.GetID())},
+		),
+		resource.WithParentResourceID(orgID),
+	)
+}
+
+// pendingGrantMetadata marks a grant that only takes effect once the invitation is accepted.
+func pendingGrantMetadata(invitation *github.Invitation) (*v2.GrantMetadata, error) {
+	metadata, err := structpb.NewStruct(map[string]interface{}{
+		"state":         invitationStatePending,
+		"invitation_id": invitation.GetID(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &v2.GrantMetadata{Metadata: metadata}, nil
+}
+
+type invitationResourceType struct {
+	resourceType *v2.ResourceType
+	client       *github.Client
+	orgCache     *orgNameCache
+}
+
+func (i *invitationResourceType) ResourceType(_ context.Context) *v2.ResourceType {
+	return i.resourceType
+}
+
+func (i *invitationResourceType) List(ctx context.Context, parentID *v2.ResourceId, pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	if parentID == nil {
+		return nil, "", nil, nil
+	}
+
+	bag, page, err := parsePageToken(pToken.Token, &v2.ResourceId{ResourceType: resourceTypeInvitation.Id})
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	orgName, err := i.orgCache.GetOrgName(ctx, parentID)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	client, err := i.orgCache.GetClient(parentID)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	invitations, resp, err := client.Organizations.ListPendingOrgInvitations(ctx, orgName, &github.ListOptions{
+		Page:    page,
+		PerPage: pToken.Size,
+	})
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("github-connector: failed to list pending org invitations: %w", err)
+	}
+
+	nextPage, reqAnnos, err := parseResp(resp)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	pageToken, err := bag.NextToken(nextPage)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	rv := make([]*v2.Resource, 0, len(invitations))
+	for _, invitation := range invitations {
+		ir, err := invitationResource(invitation, parentID)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		rv = append(rv, ir)
+	}
+
+	return rv, pageToken, reqAnnos, nil
+}
+
+func (i *invitationResourceType) Entitlements(_ context.Context, _ *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	return nil, "", nil, nil
+}
+
+func (i *invitationResourceType) Grants(_ context.Context, _ *v2.Resource, _ *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	return nil, "", nil, nil
+}
+
+// findOrgInvitation returns the pending org invitation matching the given function, or nil if there is none.
+func findOrgInvitation(
+	ctx context.Context,
+	client *github.Client,
+	orgName string,
+	match func(invitation *github.Invitation) bool,
+) (*github.Invitation, error) {
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		invitations, resp, err := client.Organizations.ListPendingOrgInvitations(ctx, orgName, opts)
+		if err != nil {
+			return nil, fmt.Errorf("github-connectorv2: failed to list pending org invitations: %w", err)
+		}
+
+		for _, invitation := range invitations {
+			if match(invitation) {
+				return invitation, nil
+			}
+		}
+
+		if resp.NextPage == 0 {
+			return nil, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// invitationForLogin matches invitations sent to the GitHub user with the given login.
+func invitationForLogin(login string) func(invitation *github.Invitation) bool {
+	return func(invitation *github.Invitation) bool {
+		return invitation.GetLogin() != "" && strings.EqualFold(invitation.GetLogin(), login)
+	}
+}
+
+// invitationForEmail matches invitations sent to the given email address.
+func invitationForEmail(email string) func(invitation *github.Invitation) bool {
+	return func(invitation *github.Invitation) bool {
+		return invitation.GetEmail() != "" && strings.EqualFold(invitation.GetEmail(), email)
+	}
+}
+
+// invitationForID matches the invitation with the given ID.
+func invitationForID(invitationID int64) func(invitation *github.Invitation) bool {
+	return func(invitation *github.Invitation) bool {
+		return invitation.GetID() == invitationID
+	}
+}
+
+// cancelOrgInvitation cancels a pending org invitation. go-github doesn't support cancelling invitations yet.
+func cancelOrgInvitation(ctx context.Context, client *github.Client, orgName string, invitationID int64) error {
+	req, err := client.NewRequest(http.MethodDelete, fmt.Sprintf("orgs/%s/invitations/%d", orgName, invitationID), nil)
+	if err != nil {
+		return err
+	}
+
+	_, err = client.Do(ctx, req, nil)
+	if err != nil {
+		return fmt.Errorf("github-connectorv2: failed to cancel org invitation: %w", err)
+	}
+
+	return nil
+}
+
+func invitationBuilder(client *github.Client, orgCache *orgNameCache) *invitationResourceType {
+	return &invitationResourceType{
+		resourceType: resourceTypeInvitation,
+		client:       client,
+		orgCache:     orgCache,
+	}
+}
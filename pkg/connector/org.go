@@ -16,17 +16,24 @@ import (
 	"github.com/google/go-github/v63/github"
 	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
 	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
 )
 
 const (
-	orgRoleMember       = "member"
-	orgRoleDirectMember = "direct_member" // invite
-	orgRoleAdmin        = "admin"
+	orgRoleMember              = "member"
+	orgRoleDirectMember        = "direct_member" // invite
+	orgRoleAdmin               = "admin"
+	orgRoleBillingManager      = "billing_manager"
+	orgRoleSecurityManager     = "security_manager"
+	orgRoleOutsideCollaborator = "outside_collaborator"
 )
 
 var orgAccessLevels = []string{
 	orgRoleAdmin,
 	orgRoleMember,
+	orgRoleBillingManager,
+	orgRoleSecurityManager,
+	orgRoleOutsideCollaborator,
 }
 
 type orgResourceType struct {
@@ -34,6 +41,10 @@ type orgResourceType struct {
 	client       *github.Client
 	orgs         map[string]struct{}
 	orgCache     *orgNameCache
+	// appInstallations is non-nil when the connector authenticates as a GitHub App.
+	appInstallations []*github.Installation
+	// enterprise is non-nil when an enterprise is configured, in which case its orgs are listed under it.
+	enterprise *enterpriseResourceType
 }
 
 func organizationResource(
@@ -52,6 +63,9 @@ func organizationResource(
 			&v2.ChildResourceType{ResourceTypeId: resourceTypeUser.Id},
 			&v2.ChildResourceType{ResourceTypeId: resourceTypeTeam.Id},
 			&v2.ChildResourceType{ResourceTypeId: resourceTypeRepository.Id},
+			&v2.ChildResourceType{ResourceTypeId: resourceTypeOrgRole.Id},
+			&v2.ChildResourceType{ResourceTypeId: resourceTypeInvitation.Id},
+			&v2.ChildResourceType{ResourceTypeId: resourceTypeIDPGroup.Id},
 		),
 	)
 }
@@ -67,6 +81,15 @@ func (o *orgResourceType) List(
 ) ([]*v2.Resource, string, annotations.Annotations, error) {
 	l := ctxzap.Extract(ctx)
 
+	// The orgs of the enterprise are listed again for every sync, which starts with the top level orgs.
+	if o.enterprise != nil && parentResourceID == nil && pToken.Token == "" {
+		o.enterprise.ResetOrgs()
+	}
+
+	if o.appInstallations != nil {
+		return o.listAppInstallationOrgs(ctx, parentResourceID)
+	}
+
 	bag, page, err := parsePageToken(pToken.Token, &v2.ResourceId{ResourceType: resourceTypeOrg.Id})
 	if err != nil {
 		return nil, "", nil, err
@@ -92,11 +115,28 @@ func (o *orgResourceType) List(
 		return nil, "", nil, err
 	}
 
+	var enterpriseOrgs map[string]struct{}
+	if o.enterprise != nil {
+		enterpriseOrgs, err = o.enterprise.Orgs(ctx)
+		if err != nil {
+			return nil, "", nil, err
+		}
+	}
+
 	var ret []*v2.Resource
 	for _, org := range orgs {
 		if _, ok := o.orgs[org.GetLogin()]; !ok && len(o.orgs) > 0 {
 			continue
 		}
+
+		// Orgs of the enterprise are listed under the enterprise, any other orgs are listed at the top level.
+		if o.enterprise != nil {
+			_, inEnterprise := enterpriseOrgs[org.GetLogin()]
+			if inEnterprise != (parentResourceID != nil) {
+				continue
+			}
+		}
+
 		membership, resp, err := o.client.Organizations.GetOrgMembership(ctx, "", org.GetLogin())
 		if err != nil {
 			if resp.StatusCode == http.StatusForbidden {
@@ -122,6 +162,48 @@ func (o *orgResourceType) List(
 	return ret, pageToken, reqAnnos, nil
 }
 
+// listAppInstallationOrgs returns the orgs the GitHub App is installed on, each fetched with its own installation client.
+// Installation tokens can't list the orgs of the authenticated user, nor do they have an org membership to check.
+func (o *orgResourceType) listAppInstallationOrgs(
+	ctx context.Context,
+	parentResourceID *v2.ResourceId,
+) ([]*v2.Resource, string, annotations.Annotations, error) {
+	var rv []*v2.Resource
+	var reqAnnos annotations.Annotations
+	for _, installation := range o.appInstallations {
+		account := installation.GetAccount()
+		if _, ok := o.orgs[account.GetLogin()]; !ok && len(o.orgs) > 0 {
+			continue
+		}
+
+		client, err := o.orgCache.GetClient(&v2.ResourceId{
+			ResourceType: resourceTypeOrg.Id,
+			Resource:     strconv.FormatInt(account.GetID(), 10),
+		})
+		if err != nil {
+			return nil, "", nil, err
+		}
+		org, resp, err := client.Organizations.Get(ctx, account.GetLogin())
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("github-connector: failed to fetch org %s: %w", account.GetLogin(), err)
+		}
+
+		_, reqAnnos, err = parseResp(resp)
+		if err != nil {
+			return nil, "", nil, err
+		}
+
+		orgResource, err := organizationResource(ctx, org, parentResourceID)
+		if err != nil {
+			return nil, "", nil, err
+		}
+
+		rv = append(rv, orgResource)
+	}
+
+	return rv, "", reqAnnos, nil
+}
+
 func (o *orgResourceType) Entitlements(
 	_ context.Context,
 	resource *v2.Resource,
@@ -134,7 +216,7 @@ func (o *orgResourceType) Entitlements(
 		entitlement.WithAnnotation(&v2.V1Identifier{
 			Id: fmt.Sprintf("org:%s:role:%s", resource.Id.Resource, orgRoleMember),
 		}),
-		entitlement.WithGrantableTo(resourceTypeUser),
+		entitlement.WithGrantableTo(resourceTypeUser, resourceTypeInvitation),
 	))
 	rv = append(rv, entitlement.NewPermissionEntitlement(resource, orgRoleAdmin,
 		entitlement.WithDisplayName(fmt.Sprintf("%s Org %s", resource.DisplayName, titleCase(orgRoleAdmin))),
@@ -142,16 +224,51 @@ func (o *orgResourceType) Entitlements(
 		entitlement.WithAnnotation(&v2.V1Identifier{
 			Id: fmt.Sprintf("org:%s:role:%s", resource.Id.Resource, orgRoleAdmin),
 		}),
+		entitlement.WithGrantableTo(resourceTypeUser, resourceTypeInvitation),
+	))
+	rv = append(rv, entitlement.NewAssignmentEntitlement(resource, orgRoleOutsideCollaborator,
+		entitlement.WithDisplayName(fmt.Sprintf("%s Org %s", resource.DisplayName, titleCase(strings.ReplaceAll(orgRoleOutsideCollaborator, "_", " ")))),
+		entitlement.WithDescription(fmt.Sprintf("Repository access to %s org in GitHub without org membership", resource.DisplayName)),
+		entitlement.WithAnnotation(&v2.V1Identifier{
+			Id: fmt.Sprintf("org:%s:role:%s", resource.Id.Resource, orgRoleOutsideCollaborator),
+		}),
 		entitlement.WithGrantableTo(resourceTypeUser),
 	))
+	rv = append(rv, entitlement.NewPermissionEntitlement(resource, orgRoleBillingManager,
+		entitlement.WithDisplayName(fmt.Sprintf("%s Org %s", resource.DisplayName, titleCase(strings.ReplaceAll(orgRoleBillingManager, "_", " ")))),
+		entitlement.WithDescription(fmt.Sprintf("Manage the billing settings and payment methods of %s org in GitHub", resource.DisplayName)),
+		entitlement.WithAnnotation(&v2.V1Identifier{
+			Id: fmt.Sprintf("org:%s:role:%s", resource.Id.Resource, orgRoleBillingManager),
+		}),
+		entitlement.WithGrantableTo(resourceTypeUser, resourceTypeInvitation),
+	))
+	rv = append(rv, entitlement.NewPermissionEntitlement(resource, orgRoleSecurityManager,
+		entitlement.WithDisplayName(fmt.Sprintf("%s Org %s", resource.DisplayName, titleCase(strings.ReplaceAll(orgRoleSecurityManager, "_", " ")))),
+		entitlement.WithDescription(fmt.Sprintf("Manage security alerts and settings of %s org in GitHub", resource.DisplayName)),
+		entitlement.WithAnnotation(&v2.V1Identifier{
+			Id: fmt.Sprintf("org:%s:role:%s", resource.Id.Resource, orgRoleSecurityManager),
+		}),
+		entitlement.WithGrantableTo(resourceTypeTeam),
+	))
 
 	return rv, "", nil, nil
 }
 
-func (o *orgResourceType) orgRoleGrant(roleName string, org *v2.Resource, principalID *v2.ResourceId, userID int64) *v2.Grant {
-	return grant.NewGrant(org, roleName, principalID, grant.WithAnnotation(&v2.V1Identifier{
+// adminMembershipGrant returns a grant of the member entitlement that is expanded onto every admin of the org, since
+// admins are members too.
+func (o *orgResourceType) adminMembershipGrant(org *v2.Resource) *v2.Grant {
+	return grant.NewGrant(org, orgRoleMember, org.Id, grant.WithAnnotation(
+		&v2.GrantExpandable{
+			EntitlementIds: []string{entitlement.NewEntitlementID(org, orgRoleAdmin)},
+		},
+	))
+}
+
+func (o *orgResourceType) orgRoleGrant(roleName string, org *v2.Resource, principalID *v2.ResourceId, userID int64, annos ...proto.Message) *v2.Grant {
+	annos = append(annos, &v2.V1Identifier{
 		Id: fmt.Sprintf("org-grant:%s:%d:%s", org.Id.Resource, userID, roleName),
-	}))
+	})
+	return grant.NewGrant(org, roleName, principalID, grant.WithAnnotation(annos...))
 }
 
 func (o *orgResourceType) Grants(
@@ -164,63 +281,214 @@ func (o *orgResourceType) Grants(
 		return nil, "", nil, err
 	}
 
-	opts := github.ListMembersOptions{
-		ListOptions: github.ListOptions{
-			Page:    page,
-			PerPage: pToken.Size,
-		},
-	}
-
 	orgName, err := o.orgCache.GetOrgName(ctx, resource.Id)
 	if err != nil {
 		return nil, "", nil, err
 	}
-
-	users, resp, err := o.client.Organizations.ListMembers(ctx, orgName, &opts)
+	client, err := o.orgCache.GetClient(resource.Id)
 	if err != nil {
-		return nil, "", nil, fmt.Errorf("github-connectorv2: failed to list org members: %w", err)
-	}
-
-	nextPage, reqAnnos, err := parseResp(resp)
-	if err != nil {
-		return nil, "", nil, fmt.Errorf("github-connectorv2: failed to parse response: %w", err)
+		return nil, "", nil, err
 	}
 
-	pageToken, err := bag.NextToken(nextPage)
-	if err != nil {
-		return nil, "", nil, err
+	listOpts := github.ListOptions{
+		Page:    page,
+		PerPage: pToken.Size,
 	}
 
 	var rv []*v2.Grant
-	for _, user := range users {
-		membership, _, err := o.client.Organizations.GetOrgMembership(ctx, user.GetLogin(), orgName)
+	var reqAnnos annotations.Annotations
+
+	switch bag.ResourceTypeID() {
+	case resourceTypeOrg.Id:
+		rv = append(rv, o.adminMembershipGrant(resource))
+
+		bag.Pop()
+		bag.Push(pagination.PageState{
+			ResourceTypeID: orgRoleSecurityManager,
+		})
+		bag.Push(pagination.PageState{
+			ResourceTypeID: orgRoleBillingManager,
+		})
+		bag.Push(pagination.PageState{
+			ResourceTypeID: orgRoleOutsideCollaborator,
+		})
+		bag.Push(pagination.PageState{
+			ResourceTypeID: resourceTypeInvitation.Id,
+		})
+		bag.Push(pagination.PageState{
+			ResourceTypeID: orgRoleMember,
+		})
+
+	case orgRoleMember:
+		users, resp, err := client.Organizations.ListMembers(ctx, orgName, &github.ListMembersOptions{ListOptions: listOpts})
 		if err != nil {
-			return nil, "", nil, fmt.Errorf("github-connectorv2: failed to get org memberships for user: %w", err)
+			return nil, "", nil, fmt.Errorf("github-connectorv2: failed to list org members: %w", err)
 		}
-		if membership.GetState() == "pending" {
-			continue
+
+		nextPage, respAnnos, err := parseResp(resp)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("github-connectorv2: failed to parse response: %w", err)
 		}
+		reqAnnos = respAnnos
 
-		ur, err := userResource(ctx, user, user.GetEmail(), nil)
+		err = bag.Next(nextPage)
 		if err != nil {
 			return nil, "", nil, err
 		}
 
-		roleName := strings.ToLower(membership.GetRole())
-		switch roleName {
-		case orgRoleAdmin:
-			rv = append(rv, o.orgRoleGrant(orgRoleAdmin, resource, ur.Id, user.GetID()))
-			rv = append(rv, o.orgRoleGrant(orgRoleMember, resource, ur.Id, user.GetID()))
+		for _, user := range users {
+			membership, _, err := client.Organizations.GetOrgMembership(ctx, user.GetLogin(), orgName)
+			if err != nil {
+				return nil, "", nil, fmt.Errorf("github-connectorv2: failed to get org memberships for user: %w", err)
+			}
+			// Pending members are granted through their invitation.
+			if membership.GetState() == invitationStatePending {
+				continue
+			}
+
+			ur, err := userResource(ctx, user, user.GetEmail(), nil)
+			if err != nil {
+				return nil, "", nil, err
+			}
 
-		case orgRoleMember:
-			rv = append(rv, o.orgRoleGrant(orgRoleMember, resource, ur.Id, user.GetID()))
+			// Admins are granted membership through the expansion of the admin entitlement.
+			roleName := strings.ToLower(membership.GetRole())
+			switch roleName {
+			case orgRoleAdmin, orgRoleMember:
+				rv = append(rv, o.orgRoleGrant(roleName, resource, ur.Id, user.GetID()))
+
+			default:
+				ctxzap.Extract(ctx).Warn("Unknown GitHub Role Name",
+					zap.String("role_name", roleName),
+					zap.String("github_username", user.GetLogin()),
+				)
+			}
+		}
 
-		default:
-			ctxzap.Extract(ctx).Warn("Unknown GitHub Role Name",
-				zap.String("role_name", roleName),
-				zap.String("github_username", user.GetLogin()),
-			)
+	case resourceTypeInvitation.Id:
+		invitations, resp, err := client.Organizations.ListPendingOrgInvitations(ctx, orgName, &listOpts)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("github-connectorv2: failed to list pending org invitations: %w", err)
 		}
+
+		nextPage, respAnnos, err := parseResp(resp)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("github-connectorv2: failed to parse response: %w", err)
+		}
+		reqAnnos = respAnnos
+
+		err = bag.Next(nextPage)
+		if err != nil {
+			return nil, "", nil, err
+		}
+
+		for _, invitation := range invitations {
+			var roleName string
+			switch invitation.GetRole() {
+			case orgRoleAdmin:
+				roleName = orgRoleAdmin
+			case orgRoleDirectMember, "reinstate":
+				roleName = orgRoleMember
+			case orgRoleBillingManager:
+				roleName = orgRoleBillingManager
+			default:
+				ctxzap.Extract(ctx).Debug("skipping org invitation with unsupported role",
+					zap.String("role_name", invitation.GetRole()),
+					zap.Int64("invitation_id", invitation.GetID()),
+				)
+				continue
+			}
+
+			ir, err := invitationResource(invitation, resource.Id)
+			if err != nil {
+				return nil, "", nil, err
+			}
+
+			pending, err := pendingGrantMetadata(invitation)
+			if err != nil {
+				return nil, "", nil, err
+			}
+
+			rv = append(rv, o.orgRoleGrant(roleName, resource, ir.Id, invitation.GetID(), pending))
+		}
+
+	case orgRoleOutsideCollaborator:
+		users, resp, err := client.Organizations.ListOutsideCollaborators(ctx, orgName, &github.ListOutsideCollaboratorsOptions{ListOptions: listOpts})
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("github-connectorv2: failed to list org outside collaborators: %w", err)
+		}
+
+		nextPage, respAnnos, err := parseResp(resp)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("github-connectorv2: failed to parse response: %w", err)
+		}
+		reqAnnos = respAnnos
+
+		err = bag.Next(nextPage)
+		if err != nil {
+			return nil, "", nil, err
+		}
+
+		for _, user := range users {
+			ur, err := userResource(ctx, user, user.GetEmail(), nil)
+			if err != nil {
+				return nil, "", nil, err
+			}
+
+			rv = append(rv, o.orgRoleGrant(orgRoleOutsideCollaborator, resource, ur.Id, user.GetID()))
+		}
+
+	case orgRoleBillingManager:
+		bag.Pop()
+
+		// The billing managers are listed once per sync, and shared with the users of the org.
+		users, err := o.orgCache.OrgUsers(ctx, resource.Id)
+		if err != nil {
+			return nil, "", nil, err
+		}
+
+		for _, user := range users.billingManagers {
+			ur, err := userResource(ctx, user, user.GetEmail(), nil)
+			if err != nil {
+				return nil, "", nil, err
+			}
+
+			rv = append(rv, o.orgRoleGrant(orgRoleBillingManager, resource, ur.Id, user.GetID()))
+		}
+
+	case orgRoleSecurityManager:
+		bag.Pop()
+
+		teams, resp, err := client.Organizations.ListSecurityManagerTeams(ctx, orgName)
+		if err != nil {
+			if resp == nil || (resp.StatusCode != http.StatusNotFound && resp.StatusCode != http.StatusForbidden) {
+				return nil, "", nil, fmt.Errorf("github-connectorv2: failed to list org security manager teams: %w", err)
+			}
+			ctxzap.Extract(ctx).Warn("can't list security manager teams", zap.String("org", orgName), zap.Error(err))
+		}
+
+		_, reqAnnos, err = parseResp(resp)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("github-connectorv2: failed to parse response: %w", err)
+		}
+
+		// Members of security manager teams are security managers too.
+		for _, team := range teams {
+			tr, err := teamResource(team, resource.Id)
+			if err != nil {
+				return nil, "", nil, err
+			}
+
+			rv = append(rv, o.orgRoleGrant(orgRoleSecurityManager, resource, tr.Id, team.GetID(), teamMembershipExpandable(tr)))
+		}
+
+	default:
+		return nil, "", nil, fmt.Errorf("unexpected resource type while fetching grants for org")
+	}
+
+	pageToken, err := bag.Marshal()
+	if err != nil {
+		return nil, "", nil, err
 	}
 
 	return rv, pageToken, reqAnnos, nil
@@ -229,7 +497,12 @@ func (o *orgResourceType) Grants(
 func (o *orgResourceType) Grant(ctx context.Context, principal *v2.Resource, en *v2.Entitlement) (annotations.Annotations, error) {
 	l := ctxzap.Extract(ctx)
 
-	if principal.Id.ResourceType != resourceTypeUser.Id {
+	// The security manager role is assigned to teams.
+	if en.Id == entitlement.NewEntitlementID(en.Resource, orgRoleSecurityManager) {
+		return nil, o.setSecurityManagerTeam(ctx, en.Resource.Id, principal, true)
+	}
+
+	if principal.Id.ResourceType != resourceTypeUser.Id && principal.Id.ResourceType != resourceTypeInvitation.Id {
 		l.Error(
 			"github-connectorv2: only users can be granted org admin",
 			zap.String("principal_type", principal.Id.ResourceType),
@@ -240,22 +513,54 @@ func (o *orgResourceType) Grant(ctx context.Context, principal *v2.Resource, en
 
 	adminRoleID := entitlement.NewEntitlementID(en.Resource, orgRoleAdmin)
 	memberRoleID := entitlement.NewEntitlementID(en.Resource, orgRoleMember)
+	billingManagerRoleID := entitlement.NewEntitlementID(en.Resource, orgRoleBillingManager)
+	outsideCollaboratorRoleID := entitlement.NewEntitlementID(en.Resource, orgRoleOutsideCollaborator)
+
+	if en.Id == outsideCollaboratorRoleID {
+		return nil, fmt.Errorf("github-connectorv2: outside collaborators can only be added by granting repository access")
+	}
 
 	orgName, err := o.orgCache.GetOrgName(ctx, en.Resource.Id)
 	if err != nil {
 		return nil, err
 	}
+	client, err := o.orgCache.GetClient(en.Resource.Id)
+	if err != nil {
+		return nil, err
+	}
 
 	principalID, err := strconv.ParseInt(principal.Id.Resource, 10, 64)
 	if err != nil {
 		return nil, err
 	}
 
-	user, _, err := o.client.Users.GetByID(ctx, principalID)
+	// Invitations can't be changed once sent, so there is nothing to do as long as the invitation is still pending.
+	if principal.Id.ResourceType == resourceTypeInvitation.Id {
+		invitation, err := findOrgInvitation(ctx, client, orgName, invitationForID(principalID))
+		if err != nil {
+			return nil, err
+		}
+		if invitation == nil {
+			return nil, fmt.Errorf("github-connectorv2: org invitation %d is no longer pending", principalID)
+		}
+		return nil, nil
+	}
+
+	user, _, err := client.Users.GetByID(ctx, principalID)
 	if err != nil {
 		return nil, fmt.Errorf("github-connectorv2: failed to get user: %w", err)
 	}
 
+	if en.Id == billingManagerRoleID {
+		err = o.grantBillingManager(ctx, client, orgName, user)
+		if err != nil {
+			return nil, err
+		}
+		// The cached billing managers are listed again the next time they're needed.
+		o.orgCache.ResetOrgUsers(en.Resource.Id)
+		return nil, nil
+	}
+
 	requestedRole := ""
 	switch en.Id {
 	case adminRoleID:
@@ -266,16 +571,26 @@ func (o *orgResourceType) Grant(ctx context.Context, principal *v2.Resource, en
 		return nil, fmt.Errorf("github-connectorv2: invalid entitlement id: %s", en.Id)
 	}
 
-	isMember, _, err := o.client.Organizations.IsMember(ctx, orgName, user.GetLogin())
+	isMember, _, err := client.Organizations.IsMember(ctx, orgName, user.GetLogin())
 	if err != nil {
 		return nil, fmt.Errorf("github-connectorv2: failed to get org membership: %w", err)
 	}
 
-	// TODO: check existing invitations. Duplicate invitations aren't allowed, so this will fail with 4xx from github.
-
 	// If user isn't a member, invite them to the org with the requested role
 	if !isMember {
-		_, _, err = o.client.Organizations.CreateOrgInvitation(ctx, orgName, &github.CreateOrgInvitationOptions{
+		// Duplicate invitations aren't allowed, so treat an existing invitation as success.
+		invitation, err := findOrgInvitation(ctx, client, orgName, invitationForLogin(user.GetLogin()))
+		if err != nil {
+			return nil, err
+		}
+		if invitation != nil {
+			l.Debug("githubv2-connector: user already has a pending invitation to the org",
+				zap.Int64("invitation_id", invitation.GetID()),
+			)
+			return nil, nil
+		}
+
+		_, _, err = client.Organizations.CreateOrgInvitation(ctx, orgName, &github.CreateOrgInvitationOptions{
 			InviteeID: user.ID,
 			Role:      &requestedRole,
 		})
@@ -291,7 +606,7 @@ func (o *orgResourceType) Grant(ctx context.Context, principal *v2.Resource, en
 	}
 
 	// If the user is a member, check to see what role they have
-	membership, _, err := o.client.Organizations.GetOrgMembership(ctx, user.GetLogin(), orgName)
+	membership, _, err := client.Organizations.GetOrgMembership(ctx, user.GetLogin(), orgName)
 	if err != nil {
 		return nil, fmt.Errorf("github-connectorv2: failed to get org membership: %w", err)
 	}
@@ -303,7 +618,7 @@ func (o *orgResourceType) Grant(ctx context.Context, principal *v2.Resource, en
 	}
 
 	// User is a member but grant is for admin, so make them an admin.
-	_, _, err = o.client.Organizations.EditOrgMembership(ctx, user.GetLogin(), orgName, &github.Membership{Role: github.String(orgRoleAdmin)})
+	_, _, err = client.Organizations.EditOrgMembership(ctx, user.GetLogin(), orgName, &github.Membership{Role: github.String(orgRoleAdmin)})
 	if err != nil {
 		return nil, fmt.Errorf("github-connectorv2: failed to make user an admin : %w", err)
 	}
@@ -317,7 +632,11 @@ func (o *orgResourceType) Revoke(ctx context.Context, grant *v2.Grant) (annotati
 	en := grant.Entitlement
 	principal := grant.Principal
 
-	if principal.Id.ResourceType != resourceTypeUser.Id {
+	if en.Id == entitlement.NewEntitlementID(en.Resource, orgRoleSecurityManager) {
+		return nil, o.setSecurityManagerTeam(ctx, en.Resource.Id, principal, false)
+	}
+
+	if principal.Id.ResourceType != resourceTypeUser.Id && principal.Id.ResourceType != resourceTypeInvitation.Id {
 		l.Error(
 			"github-connectorv2: org admin can only be revoked from users",
 			zap.String("principal_type", principal.Id.ResourceType),
@@ -328,8 +647,10 @@ func (o *orgResourceType) Revoke(ctx context.Context, grant *v2.Grant) (annotati
 
 	adminRoleID := entitlement.NewEntitlementID(en.Resource, orgRoleAdmin)
 	memberRoleID := entitlement.NewEntitlementID(en.Resource, orgRoleMember)
+	billingManagerRoleID := entitlement.NewEntitlementID(en.Resource, orgRoleBillingManager)
+	outsideCollaboratorRoleID := entitlement.NewEntitlementID(en.Resource, orgRoleOutsideCollaborator)
 
-	if en.Id != adminRoleID && en.Id != memberRoleID {
+	if en.Id != adminRoleID && en.Id != memberRoleID && en.Id != billingManagerRoleID && en.Id != outsideCollaboratorRoleID {
 		return nil, fmt.Errorf("github-connectorv2: invalid entitlement id: %s", en.Id)
 	}
 
@@ -337,35 +658,83 @@ func (o *orgResourceType) Revoke(ctx context.Context, grant *v2.Grant) (annotati
 	if err != nil {
 		return nil, err
 	}
+	client, err := o.orgCache.GetClient(en.Resource.Id)
+	if err != nil {
+		return nil, err
+	}
 
 	principalID, err := strconv.ParseInt(principal.Id.Resource, 10, 64)
 	if err != nil {
 		return nil, err
 	}
 
-	user, _, err := o.client.Users.GetByID(ctx, principalID)
+	// Invitations have a single role, so revoking either entitlement cancels the invitation.
+	if principal.Id.ResourceType == resourceTypeInvitation.Id {
+		return nil, cancelOrgInvitation(ctx, client, orgName, principalID)
+	}
+
+	user, _, err := client.Users.GetByID(ctx, principalID)
 	if err != nil {
 		return nil, fmt.Errorf("github-connectorv2: failed to get user: %w", err)
 	}
 
-	membership, _, err := o.client.Organizations.GetOrgMembership(ctx, user.GetLogin(), orgName)
+	// Removing an outside collaborator removes them from every repository in the org.
+	if en.Id == outsideCollaboratorRoleID {
+		_, err = client.Organizations.RemoveOutsideCollaborator(ctx, orgName, user.GetLogin())
+		if err != nil {
+			return nil, fmt.Errorf("github-connectorv2: failed to remove outside collaborator from org: %w", err)
+		}
+		return nil, nil
+	}
+
+	membership, _, err := client.Organizations.GetOrgMembership(ctx, user.GetLogin(), orgName)
 	if err != nil {
 		return nil, fmt.Errorf("github-connectorv2: failed to get org membership: %w", err)
 	}
 
+	if membership.GetState() == invitationStatePending {
+		invitation, err := findOrgInvitation(ctx, client, orgName, invitationForLogin(user.GetLogin()))
+		if err != nil {
+			return nil, err
+		}
+		if invitation == nil {
+			return nil, fmt.Errorf("github-connectorv2: failed to find pending org invitation for user")
+		}
+		return nil, cancelOrgInvitation(ctx, client, orgName, invitation.GetID())
+	}
+
 	if membership.GetState() != "active" {
 		return nil, fmt.Errorf("github-connectorv2: user is not an active member of the org")
 	}
 
+	// Billing managers aren't org members, so removing their membership only removes the billing manager role.
+	if en.Id == billingManagerRoleID {
+		if membership.GetRole() != orgRoleBillingManager {
+			return nil, fmt.Errorf("github-connectorv2: user is not a billing manager of the org")
+		}
+
+		_, err = client.Organizations.RemoveOrgMembership(ctx, user.GetLogin(), orgName)
+		if err != nil {
+			return nil, fmt.Errorf("github-connectorv2: failed to revoke org billing manager from user: %w", err)
+		}
+		o.orgCache.ResetOrgUsers(en.Resource.Id)
+		return nil, nil
+	}
+
 	if en.Id == memberRoleID {
-		_, err = o.client.Organizations.RemoveOrgMembership(ctx, user.GetLogin(), orgName)
+		// Removing the membership of an admin would also remove their admin role, which has to be revoked explicitly.
+		if membership.GetRole() == orgRoleAdmin {
+			return nil, fmt.Errorf("github-connectorv2: user %s is an admin of the org, revoke org admin before revoking org membership", user.GetLogin())
+		}
+
+		_, err = client.Organizations.RemoveOrgMembership(ctx, user.GetLogin(), orgName)
 		if err != nil {
 			return nil, fmt.Errorf("github-connectorv2: failed to revoke org membership from user: %w", err)
 		}
 		return nil, nil
 	}
 
-	_, _, err = o.client.Organizations.EditOrgMembership(ctx, user.GetLogin(), orgName, &github.Membership{Role: github.String(orgRoleMember)})
+	_, _, err = client.Organizations.EditOrgMembership(ctx, user.GetLogin(), orgName, &github.Membership{Role: github.String(orgRoleMember)})
 	if err != nil {
 		return nil, fmt.Errorf("github-connectorv2: failed to revoke org admin from user: %w", err)
 	}
@@ -373,7 +742,94 @@ func (o *orgResourceType) Revoke(ctx context.Context, grant *v2.Grant) (annotati
 	return nil, nil
 }
 
-func orgBuilder(client *github.Client, orgCache *orgNameCache, orgs []string) *orgResourceType {
+// grantBillingManager makes the user a billing manager of the org. Billing managers are added by invitation, like org
+// members.
+func (o *orgResourceType) grantBillingManager(ctx context.Context, client *github.Client, orgName string, user *github.User) error {
+	l := ctxzap.Extract(ctx)
+
+	membership, resp, err := client.Organizations.GetOrgMembership(ctx, user.GetLogin(), orgName)
+	if err != nil && (resp == nil || resp.StatusCode != http.StatusNotFound) {
+		return fmt.Errorf("github-connectorv2: failed to get org membership: %w", err)
+	}
+	if membership.GetRole() == orgRoleBillingManager && membership.GetState() == "active" {
+		l.Debug("githubv2-connector: user is already a billing manager of the org")
+		return nil
+	}
+
+	// Duplicate invitations aren't allowed, so treat an existing invitation as success.
+	invitation, err := findOrgInvitation(ctx, client, orgName, invitationForLogin(user.GetLogin()))
+	if err != nil {
+		return err
+	}
+	if invitation != nil {
+		l.Debug("githubv2-connector: user already has a pending invitation to the org",
+			zap.Int64("invitation_id", invitation.GetID()),
+		)
+		return nil
+	}
+
+	_, _, err = client.Organizations.CreateOrgInvitation(ctx, orgName, &github.CreateOrgInvitationOptions{
+		InviteeID: user.ID,
+		Role:      github.String(orgRoleBillingManager),
+	})
+	if err != nil {
+		return fmt.Errorf("github-connectorv2: failed to invite user to org as billing manager: %w", err)
+	}
+
+	return nil
+}
+
+// setSecurityManagerTeam assigns the security manager role of the org to the team, or removes it.
+func (o *orgResourceType) setSecurityManagerTeam(ctx context.Context, orgID *v2.ResourceId, principal *v2.Resource, assign bool) error {
+	if principal.Id.ResourceType != resourceTypeTeam.Id {
+		return fmt.Errorf("github-connectorv2: the org security manager role can only be assigned to teams")
+	}
+
+	orgName, err := o.orgCache.GetOrgName(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	client, err := o.orgCache.GetClient(orgID)
+	if err != nil {
+		return err
+	}
+
+	githubOrgID, err := parseResourceToGitHub(orgID)
+	if err != nil {
+		return err
+	}
+	teamID, err := parseResourceToGitHub(principal.Id)
+	if err != nil {
+		return err
+	}
+
+	team, _, err := client.Teams.GetTeamByID(ctx, githubOrgID, teamID)
+	if err != nil {
+		return fmt.Errorf("github-connectorv2: failed to get team %d: %w", teamID, err)
+	}
+
+	if assign {
+		_, err = client.Organizations.AddSecurityManagerTeam(ctx, orgName, team.GetSlug())
+		if err != nil {
+			return fmt.Errorf("github-connectorv2: failed to assign the security manager role to team %s: %w", team.GetSlug(), err)
+		}
+		return nil
+	}
+
+	_, err = client.Organizations.RemoveSecurityManagerTeam(ctx, orgName, team.GetSlug())
+	if err != nil {
+		return fmt.Errorf("github-connectorv2: failed to remove the security manager role from team %s: %w", team.GetSlug(), err)
+	}
+	return nil
+}
+
+func orgBuilder(
+	client *github.Client,
+	orgCache *orgNameCache,
+	orgs []string,
+	appInstallations []*github.Installation,
+	enterprise *enterpriseResourceType,
+) *orgResourceType {
 	orgMap := make(map[string]struct{})
 
 	for _, o := range orgs {
@@ -381,9 +837,11 @@ func orgBuilder(client *github.Client, orgCache *orgNameCache, orgs []string) *o
 	}
 
 	return &orgResourceType{
-		resourceType: resourceTypeOrg,
-		orgs:         orgMap,
-		client:       client,
-		orgCache:     orgCache,
+		resourceType:     resourceTypeOrg,
+		orgs:             orgMap,
+		client:           client,
+		orgCache:         orgCache,
+		appInstallations: appInstallations,
+		enterprise:       enterprise,
 	}
 }
@@ -0,0 +1,335 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/conductorone/baton-sdk/pkg/types/grant"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/google/go-github/v63/github"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+)
+
+const orgRoleAssigned = "assigned"
+
+// orgRoleAssignmentIndirect is the assignment of users that only hold an org role through one of their teams.
+const orgRoleAssignmentIndirect = "indirect"
+
+// orgRoleUser is a user assigned to an org role. go-github doesn't expose how the user was assigned the role.
+type orgRoleUser struct {
+	github.User
+	Assignment string `json:"assignment,omitempty"`
+}
+
+// orgRoleResource creates a new connector resource for a GitHub organization role. Predefined roles share their ID
+// across every org, so the org ID is part of the resource ID.
+func orgRoleResource(role *github.CustomOrgRoles, orgID *v2.ResourceId) (*v2.Resource, error) {
+	profile := map[string]interface{}{
+		"role_id":     role.GetID(),
+		"description": role.GetDescription(),
+		"orgID":       orgID.Resource,
+	}
+
+	return resource.NewRoleResource(
+		role.GetName(),
+		resourceTypeOrgRole,
+		fmt.Sprintf("%s:%d", orgID.Resource, role.GetID()),
+		[]resource.RoleTraitOption{resource.WithRoleProfile(profile)},
+		resource.WithAnnotation(
+			&v2.V1Identifier{Id: fmt.Sprintf("org_role:%s:%d", orgID.Resource, role.GetID())},
+		),
+		resource.WithParentResourceID(orgID),
+	)
+}
+
+type orgRoleResourceType struct {
+	resourceType *v2.ResourceType
+	client       *github.Client
+	orgCache     *orgNameCache
+}
+
+func (o *orgRoleResourceType) ResourceType(_ context.Context) *v2.ResourceType {
+	return o.resourceType
+}
+
+func (o *orgRoleResourceType) List(ctx context.Context, parentID *v2.ResourceId, _ *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	if parentID == nil {
+		return nil, "", nil, nil
+	}
+
+	orgName, err := o.orgCache.GetOrgName(ctx, parentID)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	client, err := o.orgCache.GetClient(parentID)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	roles, resp, err := client.Organizations.ListRoles(ctx, orgName)
+	if err != nil {
+		// Org roles aren't available on every plan or GitHub Enterprise Server version.
+		if resp != nil && (resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusForbidden) {
+			ctxzap.Extract(ctx).Debug("org roles are not available for org", zap.String("org", orgName))
+			return nil, "", nil, nil
+		}
+		return nil, "", nil, fmt.Errorf("github-connector: failed to list org roles: %w", err)
+	}
+
+	_, reqAnnos, err := parseResp(resp)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	rv := make([]*v2.Resource, 0, len(roles.CustomRepoRoles))
+	for _, role := range roles.CustomRepoRoles {
+		rr, err := orgRoleResource(role, parentID)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		rv = append(rv, rr)
+	}
+
+	return rv, "", reqAnnos, nil
+}
+
+func (o *orgRoleResourceType) Entitlements(_ context.Context, resource *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	return []*v2.Entitlement{
+		entitlement.NewAssignmentEntitlement(resource, orgRoleAssigned,
+			entitlement.WithDisplayName(fmt.Sprintf("%s Org Role", resource.DisplayName)),
+			entitlement.WithDescription(fmt.Sprintf("Assigned the %s org role in GitHub", resource.DisplayName)),
+			entitlement.WithAnnotation(&v2.V1Identifier{
+				Id: fmt.Sprintf("org_role:%s:role:%s", resource.Id.Resource, orgRoleAssigned),
+			}),
+			entitlement.WithGrantableTo(resourceTypeUser, resourceTypeTeam),
+		),
+	}, "", nil, nil
+}
+
+func (o *orgRoleResourceType) Grants(ctx context.Context, resource *v2.Resource, pToken *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	bag, page, err := parsePageToken(pToken.Token, resource.Id)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	roleID, err := parseResourceToGitHub(resource.Id)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	orgName, err := o.orgCache.GetOrgName(ctx, resource.ParentResourceId)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	client, err := o.orgCache.GetClient(resource.ParentResourceId)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	opts := &github.ListOptions{
+		Page:    page,
+		PerPage: pToken.Size,
+	}
+
+	var rv []*v2.Grant
+	var reqAnnos annotations.Annotations
+
+	switch bag.ResourceTypeID() {
+	case resourceTypeOrgRole.Id:
+		bag.Pop()
+		bag.Push(pagination.PageState{
+			ResourceTypeID: resourceTypeUser.Id,
+		})
+		bag.Push(pagination.PageState{
+			ResourceTypeID: resourceTypeTeam.Id,
+		})
+
+	case resourceTypeUser.Id:
+		users, resp, err := listOrgRoleUsers(ctx, client, orgName, roleID, opts)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("github-connector: failed to list org role users: %w", err)
+		}
+
+		nextPage, respAnnos, err := parseResp(resp)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		reqAnnos = respAnnos
+
+		err = bag.Next(nextPage)
+		if err != nil {
+			return nil, "", nil, err
+		}
+
+		for _, user := range users {
+			// Users that only hold the role through a team are expanded from the team grant.
+			if user.Assignment == orgRoleAssignmentIndirect {
+				continue
+			}
+
+			ur, err := userResource(ctx, &user.User, user.GetEmail(), nil)
+			if err != nil {
+				return nil, "", nil, err
+			}
+
+			rv = append(rv, grant.NewGrant(resource, orgRoleAssigned, ur.Id, grant.WithAnnotation(&v2.V1Identifier{
+				Id: fmt.Sprintf("org_role-grant:%s:%d:%s", resource.Id.Resource, user.GetID(), orgRoleAssigned),
+			})))
+		}
+
+	case resourceTypeTeam.Id:
+		teams, resp, err := client.Organizations.ListTeamsAssignedToOrgRole(ctx, orgName, roleID, opts)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("github-connector: failed to list org role teams: %w", err)
+		}
+
+		nextPage, respAnnos, err := parseResp(resp)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		reqAnnos = respAnnos
+
+		err = bag.Next(nextPage)
+		if err != nil {
+			return nil, "", nil, err
+		}
+
+		for _, team := range teams {
+			tr, err := teamResource(team, resource.ParentResourceId)
+			if err != nil {
+				return nil, "", nil, err
+			}
+
+			rv = append(rv, grant.NewGrant(resource, orgRoleAssigned, tr.Id, grant.WithAnnotation(
+				&v2.V1Identifier{
+					Id: fmt.Sprintf("org_role-grant:%s:%d:%s", resource.Id.Resource, team.GetID(), orgRoleAssigned),
+				},
+				teamMembershipExpandable(tr),
+			)))
+		}
+
+	default:
+		return nil, "", nil, fmt.Errorf("unexpected resource type while fetching grants for org role")
+	}
+
+	pageToken, err := bag.Marshal()
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	return rv, pageToken, reqAnnos, nil
+}
+
+func (o *orgRoleResourceType) Grant(ctx context.Context, principal *v2.Resource, en *v2.Entitlement) (annotations.Annotations, error) {
+	return nil, o.updateAssignment(ctx, http.MethodPut, principal, en)
+}
+
+func (o *orgRoleResourceType) Revoke(ctx context.Context, grant *v2.Grant) (annotations.Annotations, error) {
+	return nil, o.updateAssignment(ctx, http.MethodDelete, grant.Principal, grant.Entitlement)
+}
+
+// updateAssignment assigns (PUT) or unassigns (DELETE) the org role of the entitlement for a user or team.
+func (o *orgRoleResourceType) updateAssignment(ctx context.Context, method string, principal *v2.Resource, en *v2.Entitlement) error {
+	l := ctxzap.Extract(ctx)
+
+	if en.GetResource().GetParentResourceId() == nil {
+		return fmt.Errorf("github-connectorv2: parent resource is required to assign org roles")
+	}
+	orgID := en.Resource.ParentResourceId
+
+	roleID, err := parseResourceToGitHub(en.Resource.Id)
+	if err != nil {
+		return err
+	}
+
+	orgName, err := o.orgCache.GetOrgName(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	client, err := o.orgCache.GetClient(orgID)
+	if err != nil {
+		return err
+	}
+
+	principalID, err := strconv.ParseInt(principal.Id.Resource, 10, 64)
+	if err != nil {
+		return err
+	}
+
+	var u string
+	switch principal.Id.ResourceType {
+	case resourceTypeUser.Id:
+		user, _, err := client.Users.GetByID(ctx, principalID)
+		if err != nil {
+			return fmt.Errorf("github-connectorv2: failed to get user: %w", err)
+		}
+		u = fmt.Sprintf("orgs/%s/organization-roles/users/%s/%d", orgName, user.GetLogin(), roleID)
+
+	case resourceTypeTeam.Id:
+		githubOrgID, err := parseResourceToGitHub(orgID)
+		if err != nil {
+			return err
+		}
+		team, _, err := client.Teams.GetTeamByID(ctx, githubOrgID, principalID)
+		if err != nil {
+			return fmt.Errorf("github-connectorv2: failed to get team: %w", err)
+		}
+		u = fmt.Sprintf("orgs/%s/organization-roles/teams/%s/%d", orgName, team.GetSlug(), roleID)
+
+	default:
+		l.Error(
+			"github-connectorv2: only users and teams can be assigned org roles",
+			zap.String("principal_type", principal.Id.ResourceType),
+			zap.String("principal_id", principal.Id.Resource),
+		)
+		return fmt.Errorf("github-connectorv2: only users and teams can be assigned org roles")
+	}
+
+	// go-github doesn't support org role assignments yet.
+	req, err := client.NewRequest(method, u, nil)
+	if err != nil {
+		return err
+	}
+	_, err = client.Do(ctx, req, nil)
+	if err != nil {
+		return fmt.Errorf("github-connectorv2: failed to update org role assignment: %w", err)
+	}
+
+	return nil
+}
+
+// listOrgRoleUsers lists the users assigned to an org role, including how they were assigned the role.
+func listOrgRoleUsers(ctx context.Context, client *github.Client, orgName string, roleID int64, opts *github.ListOptions) ([]*orgRoleUser, *github.Response, error) {
+	u := fmt.Sprintf("orgs/%s/organization-roles/%d/users?page=%d", orgName, roleID, opts.Page)
+	if opts.PerPage != 0 {
+		u = fmt.Sprintf("%s&per_page=%d", u, opts.PerPage)
+	}
+
+	req, err := client.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var users []*orgRoleUser
+	resp, err := client.Do(ctx, req, &users)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return users, resp, nil
+}
+
+func orgRoleBuilder(client *github.Client, orgCache *orgNameCache) *orgRoleResourceType {
+	return &orgRoleResourceType{
+		resourceType: resourceTypeOrgRole,
+		client:       client,
+		orgCache:     orgCache,
+	}
+}
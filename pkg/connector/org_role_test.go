@@ -0,0 +1,53 @@
+package connector
+
+import (
+	"context"
+	"testing"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/stretchr/testify/require"
+
+	"github.com/conductorone/baton-github/test"
+)
+
+func TestOrgRole(t *testing.T) {
+	ctx := context.Background()
+
+	seed := seedMockGitHub(t)
+	seed.mgh.AddOrgRole(seed.organization.GetID(), "all_repo_read")
+
+	client := orgRoleBuilder(seed.client, seed.cache)
+
+	organization, _ := organizationResource(ctx, seed.organization, nil)
+	user, _ := userResource(ctx, seed.user, *seed.user.Email, nil)
+
+	roles, _, _, err := client.List(ctx, organization.Id, &pagination.Token{})
+	require.Nil(t, err)
+	require.Len(t, roles, 1)
+	require.Equal(t, organization.Id, roles[0].ParentResourceId)
+
+	entitlements, _, _, err := client.Entitlements(ctx, roles[0], &pagination.Token{})
+	require.Nil(t, err)
+	require.Len(t, entitlements, 1)
+
+	grantAnnotations, err := client.Grant(ctx, user, entitlements[0])
+	require.Nil(t, err)
+	require.Empty(t, grantAnnotations)
+
+	grants := test.ExhaustPagination(t, func(pToken *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+		return client.Grants(ctx, roles[0], pToken)
+	})
+	require.Len(t, grants, 1)
+	require.Equal(t, user.Id.Resource, grants[0].Principal.Id.Resource)
+
+	revokeAnnotations, err := client.Revoke(ctx, grants[0])
+	require.Nil(t, err)
+	require.Empty(t, revokeAnnotations)
+
+	grants = test.ExhaustPagination(t, func(pToken *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+		return client.Grants(ctx, roles[0], pToken)
+	})
+	require.Empty(t, grants)
+}
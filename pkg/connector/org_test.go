@@ -7,6 +7,7 @@ import (
 	"github.com/conductorone/baton-github/test"
 	"github.com/conductorone/baton-github/test/mocks"
 	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
 	"github.com/conductorone/baton-sdk/pkg/pagination"
 	"github.com/conductorone/baton-sdk/pkg/types/entitlement"
 	"github.com/google/go-github/v63/github"
@@ -20,10 +21,11 @@ func TestOrganization(t *testing.T) {
 		mgh := mocks.NewMockGitHub()
 
 		githubOrganization, _, _, githubUser, _ := mgh.Seed()
+		mgh.SetOrgRole(githubUser.GetID(), orgRoleMember)
 
 		githubClient := github.NewClient(mgh.Server())
 		cache := newOrgNameCache(githubClient)
-		client := orgBuilder(githubClient, cache, nil)
+		client := orgBuilder(githubClient, cache, nil, nil, nil)
 
 		organization, _ := organizationResource(ctx, githubOrganization, nil)
 		user, _ := userResource(ctx, githubUser, *githubUser.Email, nil)
@@ -37,10 +39,9 @@ func TestOrganization(t *testing.T) {
 		require.Nil(t, err)
 		require.Empty(t, grantAnnotations)
 
-		grants, nextToken, grantsAnnotations, err := client.Grants(ctx, organization, &pagination.Token{})
-		require.Nil(t, err)
-		test.AssertNoRatelimitAnnotations(t, grantsAnnotations)
-		require.Equal(t, "", nextToken)
+		grants := test.ExhaustPagination(t, func(pToken *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+			return client.Grants(ctx, organization, pToken)
+		})
 		require.Len(t, grants, 2)
 
 		grant := v2.Grant{
@@ -52,4 +53,203 @@ func TestOrganization(t *testing.T) {
 		require.Nil(t, err)
 		require.Empty(t, revokeAnnotations)
 	})
+
+	t.Run("should expand org membership from the admin entitlement", func(t *testing.T) {
+		seed := seedMockGitHub(t)
+
+		client := orgBuilder(seed.client, seed.cache, nil, nil, nil)
+
+		organization, _ := organizationResource(ctx, seed.organization, nil)
+		user, _ := userResource(ctx, seed.user, *seed.user.Email, nil)
+
+		grants := test.ExhaustPagination(t, func(pToken *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+			return client.Grants(ctx, organization, pToken)
+		})
+		require.Len(t, grants, 2)
+
+		// Admins aren't granted membership directly, the admin entitlement is expanded onto the member entitlement.
+		membershipGrant := grants[0]
+		require.Equal(t, organization.Id.Resource, membershipGrant.Principal.Id.Resource)
+		require.Equal(t, entitlement.NewEntitlementID(organization, orgRoleMember), membershipGrant.Entitlement.Id)
+		expandable := &v2.GrantExpandable{}
+		grantAnnotations := annotations.Annotations(membershipGrant.Annotations)
+		ok, err := grantAnnotations.Pick(expandable)
+		require.Nil(t, err)
+		require.True(t, ok)
+		require.Equal(t, []string{entitlement.NewEntitlementID(organization, orgRoleAdmin)}, expandable.EntitlementIds)
+
+		adminGrant := grants[1]
+		require.Equal(t, user.Id.Resource, adminGrant.Principal.Id.Resource)
+		require.Equal(t, entitlement.NewEntitlementID(organization, orgRoleAdmin), adminGrant.Entitlement.Id)
+
+		// Revoking the membership of an admin would remove them from the org, so admin has to be revoked first.
+		memberEntitlement := &v2.Entitlement{
+			Id:       entitlement.NewEntitlementID(organization, orgRoleMember),
+			Resource: organization,
+		}
+		_, err = client.Revoke(ctx, &v2.Grant{Entitlement: memberEntitlement, Principal: user})
+		require.ErrorContains(t, err, "is an admin of the org")
+
+		_, err = client.Revoke(ctx, &v2.Grant{Entitlement: adminGrant.Entitlement, Principal: user})
+		require.Nil(t, err)
+
+		grants = test.ExhaustPagination(t, func(pToken *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+			return client.Grants(ctx, organization, pToken)
+		})
+		require.Len(t, grants, 2)
+		require.Equal(t, entitlement.NewEntitlementID(organization, orgRoleMember), grants[1].Entitlement.Id)
+
+		_, err = client.Revoke(ctx, &v2.Grant{Entitlement: memberEntitlement, Principal: user})
+		require.Nil(t, err)
+	})
+
+	t.Run("should grant outside collaborators", func(t *testing.T) {
+		seed := seedMockGitHub(t)
+		githubCollaborator := seed.mgh.AddOutsideCollaborator(seed.organization.GetID(), 90)
+
+		client := orgBuilder(seed.client, seed.cache, nil, nil, nil)
+
+		organization, _ := organizationResource(ctx, seed.organization, nil)
+
+		grants := test.ExhaustPagination(t, func(pToken *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+			return client.Grants(ctx, organization, pToken)
+		})
+		require.Len(t, grants, 3)
+
+		collaboratorGrants := make([]*v2.Grant, 0)
+		for _, grant := range grants {
+			if grant.Entitlement.Id == entitlement.NewEntitlementID(organization, orgRoleOutsideCollaborator) {
+				collaboratorGrants = append(collaboratorGrants, grant)
+			}
+		}
+		require.Len(t, collaboratorGrants, 1)
+		require.Equal(t, githubCollaborator.GetLogin(), collaboratorGrants[0].Principal.Id.Resource)
+	})
+
+	t.Run("should grant and cancel pending invitations", func(t *testing.T) {
+		seed := seedMockGitHub(t)
+		seed.mgh.AddInvitation(seed.organization.GetID(), 100, "", "invitee@example.com", orgRoleDirectMember)
+		seed.mgh.AddInvitation(seed.organization.GetID(), 101, seed.user.GetLogin(), "", orgRoleAdmin)
+
+		client := orgBuilder(seed.client, seed.cache, nil, nil, nil)
+
+		organization, _ := organizationResource(ctx, seed.organization, nil)
+		user, _ := userResource(ctx, seed.user, *seed.user.Email, nil)
+
+		// The user already has a pending invitation, so no duplicate invitation is sent.
+		_, err := client.Grant(ctx, user, &v2.Entitlement{
+			Id:       entitlement.NewEntitlementID(organization, orgRoleMember),
+			Resource: organization,
+		})
+		require.Nil(t, err)
+
+		grants := test.ExhaustPagination(t, func(pToken *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+			return client.Grants(ctx, organization, pToken)
+		})
+
+		invitationGrants := make([]*v2.Grant, 0)
+		for _, grant := range grants {
+			if grant.Principal.Id.ResourceType == resourceTypeInvitation.Id {
+				invitationGrants = append(invitationGrants, grant)
+			}
+		}
+		require.Len(t, invitationGrants, 2)
+		require.Equal(t, "100", invitationGrants[0].Principal.Id.Resource)
+		require.Equal(t, entitlement.NewEntitlementID(organization, orgRoleMember), invitationGrants[0].Entitlement.Id)
+		require.Equal(t, entitlement.NewEntitlementID(organization, orgRoleAdmin), invitationGrants[1].Entitlement.Id)
+
+		metadata := &v2.GrantMetadata{}
+		grantAnnotations := annotations.Annotations(invitationGrants[0].Annotations)
+		ok, err := grantAnnotations.Pick(metadata)
+		require.Nil(t, err)
+		require.True(t, ok)
+		require.Equal(t, invitationStatePending, metadata.Metadata.GetFields()["state"].GetStringValue())
+
+		_, err = client.Revoke(ctx, invitationGrants[0])
+		require.Nil(t, err)
+
+		grants = test.ExhaustPagination(t, func(pToken *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+			return client.Grants(ctx, organization, pToken)
+		})
+		require.Len(t, grants, 3)
+	})
+	t.Run("should grant and revoke billing managers", func(t *testing.T) {
+		seed := seedMockGitHub(t)
+		githubBillingManager := seed.mgh.AddBillingManager(seed.organization.GetID(), 91)
+
+		client := orgBuilder(seed.client, seed.cache, nil, nil, nil)
+
+		organization, _ := organizationResource(ctx, seed.organization, nil)
+		billingManager, _ := userResource(ctx, githubBillingManager, "", nil)
+
+		billingManagerGrants := func() []*v2.Grant {
+			grants := test.ExhaustPagination(t, func(pToken *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+				return client.Grants(ctx, organization, pToken)
+			})
+			rv := make([]*v2.Grant, 0)
+			for _, grant := range grants {
+				if grant.Entitlement.Id == entitlement.NewEntitlementID(organization, orgRoleBillingManager) {
+					rv = append(rv, grant)
+				}
+			}
+			return rv
+		}
+
+		grants := billingManagerGrants()
+		require.Len(t, grants, 1)
+		require.Equal(t, githubBillingManager.GetLogin(), grants[0].Principal.Id.Resource)
+
+		_, err := client.Revoke(ctx, grants[0])
+		require.Nil(t, err)
+		require.Empty(t, billingManagerGrants())
+
+		_, err = client.Grant(ctx, billingManager, grants[0].Entitlement)
+		require.Nil(t, err)
+		require.Len(t, billingManagerGrants(), 1)
+	})
+
+	t.Run("should grant and revoke the security manager role of teams", func(t *testing.T) {
+		seed := seedMockGitHub(t)
+
+		client := orgBuilder(seed.client, seed.cache, nil, nil, nil)
+
+		organization, _ := organizationResource(ctx, seed.organization, nil)
+		team, _ := teamResource(seed.team, organization.Id)
+		user, _ := userResource(ctx, seed.user, *seed.user.Email, nil)
+
+		securityManagerEntitlement := &v2.Entitlement{
+			Id:       entitlement.NewEntitlementID(organization, orgRoleSecurityManager),
+			Resource: organization,
+		}
+
+		_, err := client.Grant(ctx, user, securityManagerEntitlement)
+		require.NotNil(t, err)
+
+		_, err = client.Grant(ctx, team, securityManagerEntitlement)
+		require.Nil(t, err)
+		require.True(t, seed.mgh.IsSecurityManagerTeam(seed.team.GetID()))
+
+		grants := test.ExhaustPagination(t, func(pToken *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+			return client.Grants(ctx, organization, pToken)
+		})
+		var securityManagerGrant *v2.Grant
+		for _, grant := range grants {
+			if grant.Entitlement.Id == securityManagerEntitlement.Id {
+				securityManagerGrant = grant
+			}
+		}
+		require.NotNil(t, securityManagerGrant)
+		require.Equal(t, team.Id.Resource, securityManagerGrant.Principal.Id.Resource)
+
+		// Members of the team are security managers.
+		expandable := &v2.GrantExpandable{}
+		grantAnnotations := annotations.Annotations(securityManagerGrant.Annotations)
+		ok, err := grantAnnotations.Pick(expandable)
+		require.Nil(t, err)
+		require.True(t, ok)
+
+		_, err = client.Revoke(ctx, securityManagerGrant)
+		require.Nil(t, err)
+		require.False(t, seed.mgh.IsSecurityManagerTeam(seed.team.GetID()))
+	})
 }
@@ -0,0 +1,156 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/google/go-github/v63/github"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+)
+
+// orgUsers are the users with access to an org, which the users of every org are looked up in.
+type orgUsers struct {
+	members              map[int64]struct{}
+	outsideCollaborators map[int64]struct{}
+	billingManagers      []*github.User
+}
+
+// isBillingManager returns true if the user is a billing manager of the org.
+func (u *orgUsers) isBillingManager(userID int64) bool {
+	for _, user := range u.billingManagers {
+		if user.GetID() == userID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// OrgUsers returns the members, outside collaborators and billing managers of the org, which are listed on first use
+// and cached until ResetOrgUsers is called. Orgs the connector doesn't sync aren't queried and have none.
+func (o *orgNameCache) OrgUsers(ctx context.Context, orgID *v2.ResourceId) (*orgUsers, error) {
+	synced, err := o.IsSyncedOrg(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if !synced {
+		return &orgUsers{members: make(map[int64]struct{}), outsideCollaborators: make(map[int64]struct{})}, nil
+	}
+
+	o.orgUsersMtx.Lock()
+	defer o.orgUsersMtx.Unlock()
+
+	if users, ok := o.orgUsers[orgID.GetResource()]; ok {
+		return users, nil
+	}
+
+	orgName, err := o.GetOrgName(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	client, err := o.GetClient(orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	users, err := listOrgUsers(ctx, client, orgName)
+	if err != nil {
+		return nil, err
+	}
+	o.orgUsers[orgID.GetResource()] = users
+
+	return users, nil
+}
+
+// IsSyncedUser returns true if the user is a member, outside collaborator or billing manager of any synced org, which
+// are the users the connector syncs.
+func (o *orgNameCache) IsSyncedUser(ctx context.Context, userID int64) (bool, error) {
+	orgIDs, err := o.OrgIDs(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	for _, orgID := range orgIDs {
+		users, err := o.OrgUsers(ctx, orgID)
+		if err != nil {
+			return false, err
+		}
+
+		_, member := users.members[userID]
+		_, outsideCollaborator := users.outsideCollaborators[userID]
+		if member || outsideCollaborator || users.isBillingManager(userID) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// ResetOrgUsers clears the cached users of the org, so they are listed again.
+func (o *orgNameCache) ResetOrgUsers(orgID *v2.ResourceId) {
+	o.orgUsersMtx.Lock()
+	defer o.orgUsersMtx.Unlock()
+
+	delete(o.orgUsers, orgID.GetResource())
+}
+
+func listOrgUsers(ctx context.Context, client *github.Client, orgName string) (*orgUsers, error) {
+	l := ctxzap.Extract(ctx)
+	rv := &orgUsers{
+		members:              make(map[int64]struct{}),
+		outsideCollaborators: make(map[int64]struct{}),
+	}
+
+	membersOpts := &github.ListMembersOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		users, resp, err := client.Organizations.ListMembers(ctx, orgName, membersOpts)
+		if err != nil {
+			if resp == nil || (resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusNotFound) {
+				return nil, fmt.Errorf("github-connector: failed to list members of %s: %w", orgName, err)
+			}
+			l.Warn("can't list members", zap.String("org", orgName), zap.Error(err))
+			break
+		}
+
+		for _, user := range users {
+			rv.members[user.GetID()] = struct{}{}
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		membersOpts.Page = resp.NextPage
+	}
+
+	opts := &github.ListOutsideCollaboratorsOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		users, resp, err := client.Organizations.ListOutsideCollaborators(ctx, orgName, opts)
+		if err != nil {
+			if resp == nil || (resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusNotFound) {
+				return nil, fmt.Errorf("github-connector: failed to list outside collaborators of %s: %w", orgName, err)
+			}
+			l.Warn("can't list outside collaborators", zap.String("org", orgName), zap.Error(err))
+			break
+		}
+
+		for _, user := range users {
+			rv.outsideCollaborators[user.GetID()] = struct{}{}
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	var err error
+	rv.billingManagers, err = listBillingManagers(ctx, client, orgName)
+	if err != nil {
+		return nil, err
+	}
+
+	return rv, nil
+}
@@ -3,8 +3,11 @@ package connector
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
 	"github.com/conductorone/baton-sdk/pkg/annotations"
@@ -34,12 +37,69 @@ var repoAccessLevels = []string{
 	repoPermissionAdmin,
 }
 
-// repositoryResource returns a new connector resource for a GitHub repository.
+// repoPermissionFromRoleName maps the role name GitHub returns for a collaborator or team to one of the built-in
+// repository permissions. Collaborators report the UI names ("read", "write") while teams report the API names.
+func repoPermissionFromRoleName(roleName string) (string, bool) {
+	switch strings.ToLower(roleName) {
+	case "read", repoPermissionPull:
+		return repoPermissionPull, true
+	case repoPermissionTriage:
+		return repoPermissionTriage, true
+	case "write", repoPermissionPush:
+		return repoPermissionPush, true
+	case repoPermissionMaintain:
+		return repoPermissionMaintain, true
+	case repoPermissionAdmin:
+		return repoPermissionAdmin, true
+	default:
+		return "", false
+	}
+}
+
+// effectiveRepoPermission returns the single permission a principal holds on a repository. Each built-in permission
+// implies the ones below it, so only the highest one is granted. Roles that aren't built-in, such as custom roles the
+// org no longer defines, fall back to the highest permission in the permissions map.
+func effectiveRepoPermission(roleName string, permissions map[string]bool) (string, bool) {
+	if permission, ok := repoPermissionFromRoleName(roleName); ok {
+		return permission, true
+	}
+
+	for i := len(repoAccessLevels) - 1; i >= 0; i-- {
+		if permissions[repoAccessLevels[i]] {
+			return repoAccessLevels[i], true
+		}
+	}
+
+	return "", false
+}
+
+// repositoryResource returns a new connector resource for a GitHub repository, with its metadata in the app profile.
 func repositoryResource(ctx context.Context, repo *github.Repository, parentResourceID *v2.ResourceId) (*v2.Resource, error) {
-	ret, err := resource.NewResource(
+	profile := map[string]interface{}{
+		"full_name":      repo.GetFullName(),
+		"owner":          repo.GetOwner().GetLogin(),
+		"visibility":     repositoryVisibility(repo),
+		"archived":       repo.GetArchived(),
+		"fork":           repo.GetFork(),
+		"default_branch": repo.GetDefaultBranch(),
+		"topics":         strings.Join(repo.Topics, ","),
+		"language":       repo.GetLanguage(),
+	}
+	if repo.CreatedAt != nil {
+		profile["created_at"] = repo.GetCreatedAt().Format(time.RFC3339)
+	}
+	if repo.PushedAt != nil {
+		profile["pushed_at"] = repo.GetPushedAt().Format(time.RFC3339)
+	}
+
+	ret, err := resource.NewAppResource(
 		repo.GetName(),
 		resourceTypeRepository,
 		repo.GetID(),
+		[]resource.AppTraitOption{
+			resource.WithAppProfile(profile),
+			resource.WithAppHelpURL(repo.GetHTMLURL()),
+		},
 		resource.WithAnnotation(
 			&v2.ExternalLink{Url: repo.GetHTMLURL()},
 			&v2.V1Identifier{Id: fmt.Sprintf("repo:%d", repo.GetID())},
@@ -57,6 +117,12 @@ type repositoryResourceType struct {
 	resourceType *v2.ResourceType
 	client       *github.Client
 	orgCache     *orgNameCache
+	filter       repositoryFilter
+
+	customRolesMtx sync.Mutex
+	// customRoles caches the custom repository roles of each org for the sync, keyed by org ID. It's reset when the
+	// repositories of the org are listed again.
+	customRoles map[string][]*github.CustomRepoRoles
 }
 
 func (o *repositoryResourceType) ResourceType(_ context.Context) *v2.ResourceType {
@@ -73,19 +139,31 @@ func (o *repositoryResourceType) List(ctx context.Context, parentID *v2.Resource
 		return nil, "", nil, err
 	}
 
+	// Custom roles are cached while the org is synced, and reloaded for every sync.
+	if pt.Token == "" {
+		o.customRolesMtx.Lock()
+		delete(o.customRoles, parentID.Resource)
+		o.customRolesMtx.Unlock()
+	}
+
 	orgName, err := o.orgCache.GetOrgName(ctx, parentID)
 	if err != nil {
 		return nil, "", nil, err
 	}
 
 	opts := &github.RepositoryListByOrgOptions{
+		Type: o.filter.listType(),
 		ListOptions: github.ListOptions{
 			Page:    page,
 			PerPage: pt.Size,
 		},
 	}
 
-	repos, resp, err := o.client.Repositories.ListByOrg(ctx, orgName, opts)
+	client, err := o.orgCache.GetClient(parentID)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	repos, resp, err := client.Repositories.ListByOrg(ctx, orgName, opts)
 	if err != nil {
 		return nil, "", nil, fmt.Errorf("github-connector: failed to list repositories: %w", err)
 	}
@@ -102,6 +180,10 @@ func (o *repositoryResourceType) List(ctx context.Context, parentID *v2.Resource
 
 	rv := make([]*v2.Resource, 0, len(repos))
 	for _, repo := range repos {
+		if !o.filter.matches(repo) {
+			continue
+		}
+
 		rr, err := repositoryResource(ctx, repo, parentID)
 		if err != nil {
 			return nil, "", nil, err
@@ -112,8 +194,73 @@ func (o *repositoryResourceType) List(ctx context.Context, parentID *v2.Resource
 	return rv, pageToken, reqAnnos, nil
 }
 
-func (o *repositoryResourceType) Entitlements(_ context.Context, resource *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
-	rv := make([]*v2.Entitlement, 0, len(repoAccessLevels))
+// listCustomRepoRoles returns the custom repository roles defined by the org. Custom roles are only available on
+// GitHub Enterprise Cloud, so orgs that can't list them are treated as having none.
+func (o *repositoryResourceType) listCustomRepoRoles(ctx context.Context, orgID *v2.ResourceId) ([]*github.CustomRepoRoles, error) {
+	o.customRolesMtx.Lock()
+	defer o.customRolesMtx.Unlock()
+
+	if roles, ok := o.customRoles[orgID.Resource]; ok {
+		return roles, nil
+	}
+
+	orgName, err := o.orgCache.GetOrgName(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := o.orgCache.GetClient(orgID)
+	if err != nil {
+		return nil, err
+	}
+	roles, resp, err := client.Organizations.ListCustomRepoRoles(ctx, orgName)
+	if err != nil {
+		if resp == nil || (resp.StatusCode != http.StatusNotFound && resp.StatusCode != http.StatusForbidden) {
+			return nil, fmt.Errorf("github-connector: failed to list custom repository roles: %w", err)
+		}
+		ctxzap.Extract(ctx).Debug("custom repository roles are not available for org", zap.String("org", orgName))
+	}
+
+	var customRoles []*github.CustomRepoRoles
+	if roles != nil {
+		customRoles = roles.CustomRepoRoles
+	}
+	o.customRoles[orgID.Resource] = customRoles
+
+	return customRoles, nil
+}
+
+// customRepoRoleNames returns the set of custom repository role names defined by the org.
+func (o *repositoryResourceType) customRepoRoleNames(ctx context.Context, orgID *v2.ResourceId) (map[string]struct{}, error) {
+	roles, err := o.listCustomRepoRoles(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	rv := make(map[string]struct{}, len(roles))
+	for _, role := range roles {
+		rv[role.GetName()] = struct{}{}
+	}
+
+	return rv, nil
+}
+
+// repoRole returns the entitlement slug for the role a principal holds on a repository, preferring custom roles.
+func repoRole(customRoleNames map[string]struct{}, roleName string, permissions map[string]bool) (string, bool) {
+	if _, ok := customRoleNames[roleName]; ok {
+		return roleName, true
+	}
+
+	return effectiveRepoPermission(roleName, permissions)
+}
+
+func (o *repositoryResourceType) Entitlements(ctx context.Context, resource *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	customRoles, err := o.listCustomRepoRoles(ctx, resource.ParentResourceId)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	rv := make([]*v2.Entitlement, 0, len(repoAccessLevels)+len(customRoles))
 	for _, level := range repoAccessLevels {
 		rv = append(rv, entitlement.NewPermissionEntitlement(resource, level,
 			entitlement.WithDisplayName(fmt.Sprintf("%s Repo %s", resource.DisplayName, titleCase(level))),
@@ -125,6 +272,22 @@ func (o *repositoryResourceType) Entitlements(_ context.Context, resource *v2.Re
 		))
 	}
 
+	for _, role := range customRoles {
+		description := role.GetDescription()
+		if description == "" {
+			description = fmt.Sprintf("Custom %s role access to %s repository in GitHub", role.GetName(), resource.DisplayName)
+		}
+
+		rv = append(rv, entitlement.NewPermissionEntitlement(resource, role.GetName(),
+			entitlement.WithDisplayName(fmt.Sprintf("%s Repo %s", resource.DisplayName, role.GetName())),
+			entitlement.WithDescription(description),
+			entitlement.WithAnnotation(&v2.V1Identifier{
+				Id: fmt.Sprintf("repo:%s:role:%s", resource.Id.Resource, role.GetName()),
+			}),
+			entitlement.WithGrantableTo(resourceTypeUser, resourceTypeTeam),
+		))
+	}
+
 	return rv, "", nil, nil
 }
 
@@ -143,6 +306,16 @@ func (o *repositoryResourceType) Grants(
 		return nil, "", nil, err
 	}
 
+	client, err := o.orgCache.GetClient(resource.ParentResourceId)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	customRoleNames, err := o.customRepoRoleNames(ctx, resource.ParentResourceId)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
 	var rv []*v2.Grant
 	var reqAnnos annotations.Annotations
 
@@ -157,11 +330,12 @@ func (o *repositoryResourceType) Grants(
 		})
 
 	case resourceTypeUser.Id:
+		// Only direct collaborators are granted here, access inherited through teams is expanded from the team grants.
 		opts := &github.ListCollaboratorsOptions{
-			Affiliation: "all",
+			Affiliation: "direct",
 			ListOptions: github.ListOptions{Page: page},
 		}
-		users, resp, err := o.client.Repositories.ListCollaborators(ctx, orgName, resource.DisplayName, opts)
+		users, resp, err := client.Repositories.ListCollaborators(ctx, orgName, resource.DisplayName, opts)
 		if err != nil {
 			return nil, "", nil, fmt.Errorf("github-connector: failed to list repos: %w", err)
 		}
@@ -178,29 +352,32 @@ func (o *repositoryResourceType) Grants(
 		}
 
 		for _, user := range users {
-			for permission, hasPermission := range user.Permissions {
-				if !hasPermission {
-					continue
-				}
-
-				ur, err := userResource(ctx, user, user.GetEmail(), nil)
-				if err != nil {
-					return nil, "", nil, err
-				}
-
-				grant := grant.NewGrant(resource, permission, ur.Id, grant.WithAnnotation(&v2.V1Identifier{
-					Id: fmt.Sprintf("repo-grant:%s:%d:%s", resource.Id.Resource, user.GetID(), permission),
-				}))
-				grant.Principal = ur
-				rv = append(rv, grant)
+			permission, ok := repoRole(customRoleNames, user.GetRoleName(), user.Permissions)
+			if !ok {
+				ctxzap.Extract(ctx).Warn("Unknown GitHub Repository Role Name",
+					zap.String("role_name", user.GetRoleName()),
+					zap.String("github_username", user.GetLogin()),
+				)
+				continue
 			}
+
+			ur, err := userResource(ctx, user, user.GetEmail(), nil)
+			if err != nil {
+				return nil, "", nil, err
+			}
+
+			grant := grant.NewGrant(resource, permission, ur.Id, grant.WithAnnotation(&v2.V1Identifier{
+				Id: fmt.Sprintf("repo-grant:%s:%d:%s", resource.Id.Resource, user.GetID(), permission),
+			}))
+			grant.Principal = ur
+			rv = append(rv, grant)
 		}
 
 	case resourceTypeTeam.Id:
 		opts := &github.ListOptions{
 			Page: page,
 		}
-		teams, resp, err := o.client.Repositories.ListTeams(ctx, orgName, resource.DisplayName, opts)
+		teams, resp, err := client.Repositories.ListTeams(ctx, orgName, resource.DisplayName, opts)
 		if err != nil {
 			return nil, "", nil, fmt.Errorf("github-connector: failed to list repos: %w", err)
 		}
@@ -217,20 +394,26 @@ func (o *repositoryResourceType) Grants(
 		}
 
 		for _, team := range teams {
-			for permission, hasPermission := range team.Permissions {
-				if !hasPermission {
-					continue
-				}
+			permission, ok := repoRole(customRoleNames, team.GetPermission(), team.Permissions)
+			if !ok {
+				ctxzap.Extract(ctx).Warn("Unknown GitHub Repository Role Name",
+					zap.String("role_name", team.GetPermission()),
+					zap.String("github_team", team.GetSlug()),
+				)
+				continue
+			}
 
-				tr, err := teamResource(team, resource.ParentResourceId)
-				if err != nil {
-					return nil, "", nil, err
-				}
+			tr, err := teamResource(team, resource.ParentResourceId)
+			if err != nil {
+				return nil, "", nil, err
+			}
 
-				rv = append(rv, grant.NewGrant(resource, permission, tr.Id, grant.WithAnnotation(&v2.V1Identifier{
+			rv = append(rv, grant.NewGrant(resource, permission, tr.Id, grant.WithAnnotation(
+				&v2.V1Identifier{
 					Id: fmt.Sprintf("repo-grant:%s:%d:%s", resource.Id.Resource, team.GetID(), permission),
-				})))
-			}
+				},
+				teamMembershipExpandable(tr),
+			)))
 		}
 	default:
 		return nil, "", nil, fmt.Errorf("unexpected resource type while fetching grants for repo")
@@ -252,7 +435,12 @@ func (o *repositoryResourceType) Grant(ctx context.Context, principal *v2.Resour
 		return nil, err
 	}
 
-	repo, _, err := o.client.Repositories.GetByID(ctx, repoID)
+	client, err := o.orgCache.GetClient(en.Resource.ParentResourceId)
+	if err != nil {
+		return nil, err
+	}
+
+	repo, _, err := client.Repositories.GetByID(ctx, repoID)
 	if err != nil {
 		return nil, fmt.Errorf("github-connectorv2: failed to get repository: %w", err)
 	}
@@ -272,12 +460,12 @@ func (o *repositoryResourceType) Grant(ctx context.Context, principal *v2.Resour
 
 	switch principal.Id.ResourceType {
 	case resourceTypeUser.Id:
-		user, _, err := o.client.Users.GetByID(ctx, principalID)
+		user, _, err := client.Users.GetByID(ctx, principalID)
 		if err != nil {
 			return nil, fmt.Errorf("github-connectorv2: failed to get user: %w", err)
 		}
 
-		_, _, e := o.client.Repositories.AddCollaborator(
+		_, _, e := client.Repositories.AddCollaborator(
 			ctx,
 			repo.GetOwner().GetLogin(),
 			repo.GetName(),
@@ -289,12 +477,12 @@ func (o *repositoryResourceType) Grant(ctx context.Context, principal *v2.Resour
 			return nil, fmt.Errorf("github-connectorv2: failed to add user to a repository: %w", e)
 		}
 	case resourceTypeTeam.Id:
-		team, _, err := o.client.Teams.GetTeamByID(ctx, org.GetID(), principalID)
+		team, _, err := client.Teams.GetTeamByID(ctx, org.GetID(), principalID)
 		if err != nil {
 			return nil, fmt.Errorf("github-connectorv2: failed to get team: %w", err)
 		}
 
-		_, err = o.client.Teams.AddTeamRepoBySlug(ctx, org.GetLogin(), team.GetSlug(), repo.GetOwner().GetLogin(), repo.GetName(), &github.TeamAddTeamRepoOptions{
+		_, err = client.Teams.AddTeamRepoBySlug(ctx, org.GetLogin(), team.GetSlug(), repo.GetOwner().GetLogin(), repo.GetName(), &github.TeamAddTeamRepoOptions{
 			Permission: permission,
 		})
 		if err != nil {
@@ -323,7 +511,12 @@ func (o *repositoryResourceType) Revoke(ctx context.Context, grant *v2.Grant) (a
 		return nil, err
 	}
 
-	repo, _, err := o.client.Repositories.GetByID(ctx, repoID)
+	client, err := o.orgCache.GetClient(en.Resource.ParentResourceId)
+	if err != nil {
+		return nil, err
+	}
+
+	repo, _, err := client.Repositories.GetByID(ctx, repoID)
 	if err != nil {
 		return nil, fmt.Errorf("github-connectorv2: failed to get repository: %w", err)
 	}
@@ -337,22 +530,22 @@ func (o *repositoryResourceType) Revoke(ctx context.Context, grant *v2.Grant) (a
 
 	switch principal.Id.ResourceType {
 	case resourceTypeUser.Id:
-		user, _, err := o.client.Users.GetByID(ctx, principalID)
+		user, _, err := client.Users.GetByID(ctx, principalID)
 		if err != nil {
 			return nil, fmt.Errorf("github-connectorv2: failed to get user: %w", err)
 		}
 
-		_, e := o.client.Repositories.RemoveCollaborator(ctx, repo.GetOwner().GetLogin(), repo.GetName(), user.GetLogin())
+		_, e := client.Repositories.RemoveCollaborator(ctx, repo.GetOwner().GetLogin(), repo.GetName(), user.GetLogin())
 		if e != nil {
 			return nil, fmt.Errorf("github-connectorv2: failed to remove user from repo: %w", e)
 		}
 	case resourceTypeTeam.Id:
-		team, _, err := o.client.Teams.GetTeamByID(ctx, org.GetID(), principalID)
+		team, _, err := client.Teams.GetTeamByID(ctx, org.GetID(), principalID)
 		if err != nil {
 			return nil, fmt.Errorf("github-connectorv2: failed to get team: %w", err)
 		}
 
-		_, err = o.client.Teams.RemoveTeamRepoBySlug(ctx, org.GetLogin(), team.GetSlug(), repo.GetOwner().GetLogin(), repo.GetName())
+		_, err = client.Teams.RemoveTeamRepoBySlug(ctx, org.GetLogin(), team.GetSlug(), repo.GetOwner().GetLogin(), repo.GetName())
 		if err != nil {
 			return nil, fmt.Errorf("github-connectorv2: failed to remove team from repo: %w", err)
 		}
@@ -368,10 +561,12 @@ func (o *repositoryResourceType) Revoke(ctx context.Context, grant *v2.Grant) (a
 	return nil, nil
 }
 
-func repositoryBuilder(client *github.Client, orgCache *orgNameCache) *repositoryResourceType {
+func repositoryBuilder(client *github.Client, orgCache *orgNameCache, filter repositoryFilter) *repositoryResourceType {
 	return &repositoryResourceType{
 		resourceType: resourceTypeRepository,
 		client:       client,
 		orgCache:     orgCache,
+		filter:       filter,
+		customRoles:  make(map[string][]*github.CustomRepoRoles),
 	}
 }
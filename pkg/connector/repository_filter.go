@@ -0,0 +1,136 @@
+package connector
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/google/go-github/v63/github"
+)
+
+const (
+	repoVisibilityPublic   = "public"
+	repoVisibilityPrivate  = "private"
+	repoVisibilityInternal = "internal"
+)
+
+// repositoryFilter limits the repositories that are synced. The zero value syncs every repository.
+type repositoryFilter struct {
+	// include and exclude are glob patterns matched against repository names, ignoring case. Repositories are synced
+	// if they match any include pattern, or there are none, and match no exclude pattern.
+	include []string
+	exclude []string
+	// skipArchived and skipForks skip archived and forked repositories.
+	skipArchived bool
+	skipForks    bool
+	// visibilities are the visibilities of the repositories that are synced, every visibility if empty.
+	visibilities []string
+	// topics are synced repositories must have at least one of, if any are set.
+	topics []string
+}
+
+func newRepositoryFilter(cfg Config) (repositoryFilter, error) {
+	f := repositoryFilter{
+		skipArchived: cfg.SkipArchivedRepos,
+		skipForks:    cfg.SkipForkRepos,
+	}
+
+	for _, pattern := range cfg.RepoInclude {
+		pattern = strings.ToLower(pattern)
+		if _, err := path.Match(pattern, ""); err != nil {
+			return repositoryFilter{}, fmt.Errorf("github-connector: invalid repository include pattern %s: %w", pattern, err)
+		}
+		f.include = append(f.include, pattern)
+	}
+
+	for _, pattern := range cfg.RepoExclude {
+		pattern = strings.ToLower(pattern)
+		if _, err := path.Match(pattern, ""); err != nil {
+			return repositoryFilter{}, fmt.Errorf("github-connector: invalid repository exclude pattern %s: %w", pattern, err)
+		}
+		f.exclude = append(f.exclude, pattern)
+	}
+
+	for _, visibility := range cfg.RepoVisibility {
+		visibility = strings.ToLower(visibility)
+		switch visibility {
+		case repoVisibilityPublic, repoVisibilityPrivate, repoVisibilityInternal:
+		default:
+			return repositoryFilter{}, fmt.Errorf("github-connector: invalid repository visibility %s, must be one of %s, %s or %s",
+				visibility, repoVisibilityPublic, repoVisibilityPrivate, repoVisibilityInternal)
+		}
+		f.visibilities = append(f.visibilities, visibility)
+	}
+
+	for _, topic := range cfg.RepoTopics {
+		f.topics = append(f.topics, strings.ToLower(topic))
+	}
+
+	return f, nil
+}
+
+// matches returns true if the repository is synced.
+func (f repositoryFilter) matches(repo *github.Repository) bool {
+	if f.skipArchived && repo.GetArchived() {
+		return false
+	}
+
+	if f.skipForks && repo.GetFork() {
+		return false
+	}
+
+	if len(f.visibilities) > 0 && !slices.Contains(f.visibilities, repositoryVisibility(repo)) {
+		return false
+	}
+
+	if len(f.topics) > 0 && !slices.ContainsFunc(repo.Topics, func(topic string) bool {
+		return slices.Contains(f.topics, strings.ToLower(topic))
+	}) {
+		return false
+	}
+
+	name := strings.ToLower(repo.GetName())
+	if len(f.include) > 0 && !matchesAny(f.include, name) {
+		return false
+	}
+
+	return !matchesAny(f.exclude, name)
+}
+
+// listType returns the type of repositories to list from the org, so that GitHub skips what the filter would.
+func (f repositoryFilter) listType() string {
+	if f.skipForks {
+		return "sources"
+	}
+
+	if len(f.visibilities) == 1 && f.visibilities[0] != repoVisibilityInternal {
+		return f.visibilities[0]
+	}
+
+	return "all"
+}
+
+// repositoryVisibility returns the visibility of a repository. GitHub Enterprise Server versions that don't return the
+// visibility only have public and private repositories.
+func repositoryVisibility(repo *github.Repository) string {
+	if visibility := repo.GetVisibility(); visibility != "" {
+		return strings.ToLower(visibility)
+	}
+
+	if repo.GetPrivate() {
+		return repoVisibilityPrivate
+	}
+
+	return repoVisibilityPublic
+}
+
+func matchesAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+
+	return false
+}
@@ -3,10 +3,13 @@ package connector
 import (
 	"context"
 	"testing"
+	"time"
 
 	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
 	"github.com/conductorone/baton-sdk/pkg/pagination"
 	entitlement2 "github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
 	"github.com/google/go-github/v63/github"
 	"github.com/stretchr/testify/require"
 
@@ -24,7 +27,7 @@ func TestRepository(t *testing.T) {
 
 		githubClient := github.NewClient(mgh.Server())
 		cache := newOrgNameCache(githubClient)
-		client := repositoryBuilder(githubClient, cache)
+		client := repositoryBuilder(githubClient, cache, repositoryFilter{})
 
 		organization, _ := organizationResource(ctx, githubOrganization, nil)
 		repository, _ := repositoryResource(ctx, githubRepository, organization.Id)
@@ -65,6 +68,7 @@ func TestRepository(t *testing.T) {
 		}
 
 		require.Len(t, grants, 1)
+		require.Equal(t, entitlement.Id, grants[0].Entitlement.Id)
 
 		grant := v2.Grant{
 			Entitlement: &entitlement,
@@ -75,4 +79,206 @@ func TestRepository(t *testing.T) {
 		require.Nil(t, err)
 		require.Empty(t, revokeAnnotations)
 	})
+
+	t.Run("should expand team grants to team members", func(t *testing.T) {
+		seed := seedMockGitHub(t)
+		seed.mgh.AddRepositoryTeam(seed.repository.GetID(), seed.team.GetID(), "push")
+
+		client := repositoryBuilder(seed.client, seed.cache, repositoryFilter{})
+
+		organization, _ := organizationResource(ctx, seed.organization, nil)
+		repository, _ := repositoryResource(ctx, seed.repository, organization.Id)
+		team, _ := teamResource(seed.team, organization.Id)
+
+		grants := test.ExhaustPagination(t, func(pToken *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+			return client.Grants(ctx, repository, pToken)
+		})
+		require.Len(t, grants, 1)
+		require.Equal(t, team.Id.Resource, grants[0].Principal.Id.Resource)
+
+		expandable := &v2.GrantExpandable{}
+		grantAnnotations := annotations.Annotations(grants[0].Annotations)
+		ok, err := grantAnnotations.Pick(expandable)
+		require.Nil(t, err)
+		require.True(t, ok)
+		require.ElementsMatch(t, []string{
+			entitlement2.NewEntitlementID(team, teamRoleMember),
+			entitlement2.NewEntitlementID(team, teamRoleMaintainer),
+		}, expandable.EntitlementIds)
+	})
+}
+
+func TestRepositoryCustomRoles(t *testing.T) {
+	ctx := context.Background()
+
+	seed := seedMockGitHub(t)
+	seed.mgh.AddCustomRepoRole(seed.organization.GetID(), "security-reviewer")
+
+	client := repositoryBuilder(seed.client, seed.cache, repositoryFilter{})
+
+	organization, _ := organizationResource(ctx, seed.organization, nil)
+	repository, _ := repositoryResource(ctx, seed.repository, organization.Id)
+	user, _ := userResource(ctx, seed.user, *seed.user.Email, nil)
+
+	entitlements, _, _, err := client.Entitlements(ctx, repository, &pagination.Token{})
+	require.Nil(t, err)
+	require.Len(t, entitlements, len(repoAccessLevels)+1)
+
+	customEntitlementID := entitlement2.NewEntitlementID(repository, "security-reviewer")
+	require.Equal(t, customEntitlementID, entitlements[len(entitlements)-1].Id)
+
+	_, err = client.Grant(ctx, user, entitlements[len(entitlements)-1])
+	require.Nil(t, err)
+
+	grants := test.ExhaustPagination(t, func(pToken *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+		return client.Grants(ctx, repository, pToken)
+	})
+	require.Len(t, grants, 1)
+	require.Equal(t, customEntitlementID, grants[0].Entitlement.Id)
+
+	// Custom roles added since are picked up when the repositories are listed again on the next sync.
+	seed.mgh.AddCustomRepoRole(seed.organization.GetID(), "release-manager")
+	entitlements, _, _, err = client.Entitlements(ctx, repository, &pagination.Token{})
+	require.Nil(t, err)
+	require.Len(t, entitlements, len(repoAccessLevels)+1)
+
+	_ = test.ExhaustPagination(t, func(pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+		return client.List(ctx, organization.Id, pToken)
+	})
+	entitlements, _, _, err = client.Entitlements(ctx, repository, &pagination.Token{})
+	require.Nil(t, err)
+	require.Len(t, entitlements, len(repoAccessLevels)+2)
+}
+
+func TestEffectiveRepoPermission(t *testing.T) {
+	testCases := []struct {
+		roleName    string
+		permissions map[string]bool
+		expected    string
+	}{
+		{"read", nil, repoPermissionPull},
+		{"write", nil, repoPermissionPush},
+		{"admin", map[string]bool{"pull": true, "triage": true, "push": true, "maintain": true, "admin": true}, repoPermissionAdmin},
+		{"push", nil, repoPermissionPush},
+		{"security-reviewer", map[string]bool{"pull": true, "triage": true}, repoPermissionTriage},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.roleName, func(t *testing.T) {
+			permission, ok := effectiveRepoPermission(testCase.roleName, testCase.permissions)
+			require.True(t, ok)
+			require.Equal(t, testCase.expected, permission)
+		})
+	}
+
+	_, ok := effectiveRepoPermission("", nil)
+	require.False(t, ok)
+}
+
+func TestRepositoryFilter(t *testing.T) {
+	ctx := context.Background()
+
+	mgh := mocks.NewMockGitHub()
+
+	githubOrganization, _, _, _, _ := mgh.Seed()
+	mgh.AddRepository(githubOrganization.GetID(), github.Repository{
+		ID:         github.Int64(35),
+		Name:       github.String("service-api"),
+		Visibility: github.String("private"),
+		Topics:     []string{"backend"},
+	})
+	mgh.AddRepository(githubOrganization.GetID(), github.Repository{
+		ID:         github.Int64(36),
+		Name:       github.String("service-legacy"),
+		Visibility: github.String("internal"),
+		Archived:   github.Bool(true),
+	})
+	mgh.AddRepository(githubOrganization.GetID(), github.Repository{
+		ID:         github.Int64(37),
+		Name:       github.String("Service-Fork"),
+		Visibility: github.String("public"),
+		Fork:       github.Bool(true),
+	})
+
+	githubClient := github.NewClient(mgh.Server())
+	organization, _ := organizationResource(ctx, githubOrganization, nil)
+
+	testCases := []struct {
+		name     string
+		cfg      Config
+		expected []string
+	}{
+		{"no filter", Config{}, []string{"repository-34", "service-api", "service-legacy", "Service-Fork"}},
+		{"include", Config{RepoInclude: []string{"service-*"}}, []string{"service-api", "service-legacy", "Service-Fork"}},
+		{"exclude", Config{RepoInclude: []string{"service-*"}, RepoExclude: []string{"*-legacy"}}, []string{"service-api", "Service-Fork"}},
+		{"archived", Config{SkipArchivedRepos: true}, []string{"repository-34", "service-api", "Service-Fork"}},
+		{"forks", Config{SkipForkRepos: true}, []string{"repository-34", "service-api", "service-legacy"}},
+		{"visibility", Config{RepoVisibility: []string{"private", "Internal"}}, []string{"service-api", "service-legacy"}},
+		{"topics", Config{RepoTopics: []string{"Backend"}}, []string{"service-api"}},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			filter, err := newRepositoryFilter(testCase.cfg)
+			require.Nil(t, err)
+
+			client := repositoryBuilder(githubClient, newOrgNameCache(githubClient), filter)
+			repositories := test.ExhaustPagination(t, func(pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+				return client.List(ctx, organization.Id, pToken)
+			})
+
+			names := make([]string, 0, len(repositories))
+			for _, repository := range repositories {
+				names = append(names, repository.DisplayName)
+			}
+			require.ElementsMatch(t, testCase.expected, names)
+		})
+	}
+
+	t.Run("should reject invalid filters", func(t *testing.T) {
+		_, err := newRepositoryFilter(Config{RepoInclude: []string{"service-["}})
+		require.NotNil(t, err)
+
+		_, err = newRepositoryFilter(Config{RepoVisibility: []string{"secret"}})
+		require.NotNil(t, err)
+	})
+}
+
+func TestRepositoryProfile(t *testing.T) {
+	ctx := context.Background()
+
+	githubRepository := &github.Repository{
+		ID:            github.Int64(34),
+		Name:          github.String("service-api"),
+		FullName:      github.String("organization-12/service-api"),
+		Owner:         &github.User{Login: github.String("organization-12")},
+		Private:       github.Bool(true),
+		Archived:      github.Bool(true),
+		DefaultBranch: github.String("main"),
+		Topics:        []string{"backend", "production"},
+		Language:      github.String("Go"),
+		CreatedAt:     &github.Timestamp{Time: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)},
+	}
+
+	repository, err := repositoryResource(ctx, githubRepository, nil)
+	require.Nil(t, err)
+
+	appTrait, err := resource.GetAppTrait(repository)
+	require.Nil(t, err)
+
+	expected := map[string]string{
+		"full_name":      "organization-12/service-api",
+		"owner":          "organization-12",
+		"visibility":     "private",
+		"default_branch": "main",
+		"topics":         "backend,production",
+		"language":       "Go",
+		"created_at":     "2020-01-02T03:04:05Z",
+	}
+	for key, value := range expected {
+		actual, ok := resource.GetProfileStringValue(appTrait.Profile, key)
+		require.True(t, ok, key)
+		require.Equal(t, value, actual, key)
+	}
+	require.True(t, appTrait.Profile.Fields["archived"].GetBoolValue())
+	require.False(t, appTrait.Profile.Fields["fork"].GetBoolValue())
+	require.NotContains(t, appTrait.Profile.Fields, "pushed_at")
 }
@@ -0,0 +1,130 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/shurcooL/githubv4"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const externalIdentitiesPageSize = 100
+
+// externalIdentity is the identity from the org's identity provider that a member has linked to their GitHub account.
+// Members sign in with a SAML identity, which is provisioned with a SCIM identity when the org uses SCIM.
+type externalIdentity struct {
+	// guid is the ID of the identity, which is the SCIM user ID for identities provisioned through SCIM.
+	guid         string
+	nameID       string
+	samlEmails   []string
+	scimUserName string
+	scimEmails   []string
+}
+
+// userEmails returns the primary email of the identity and any other emails. The SAML NameID is preferred when it's an
+// email, followed by the SAML emails and then the SCIM emails.
+func (e *externalIdentity) userEmails() (string, []string) {
+	var primary string
+	var extra []string
+	for _, email := range append(append([]string{e.nameID}, e.samlEmails...), e.scimEmails...) {
+		if !isEmail(email) || strings.EqualFold(email, primary) || containsFold(extra, email) {
+			continue
+		}
+
+		if primary == "" {
+			primary = email
+		} else {
+			extra = append(extra, email)
+		}
+	}
+
+	return primary, extra
+}
+
+// withExternalIdentity sets the details of the identity provider identity the user is linked to.
+func withExternalIdentity(identity *externalIdentity) userResourceOption {
+	return func(profile map[string]interface{}) []resource.UserTraitOption {
+		profile["external_identity_guid"] = identity.guid
+		if identity.nameID != "" {
+			profile["saml_name_id"] = identity.nameID
+		}
+		if identity.scimUserName != "" {
+			profile["scim_username"] = identity.scimUserName
+		}
+		if len(identity.scimEmails) > 0 {
+			profile["scim_emails"] = strings.Join(identity.scimEmails, ",")
+		}
+		return nil
+	}
+}
+
+// listExternalIdentities returns the external identities of the org members by their lowercase login. The identities
+// are listed in pages of 100, rather than looked up for each member.
+func listExternalIdentities(
+	ctx context.Context,
+	graphqlClient *githubv4.Client,
+	orgName string,
+) (map[string]*externalIdentity, *v2.RateLimitDescription, error) {
+	rv := make(map[string]*externalIdentity)
+	var rateLimit *v2.RateLimitDescription
+	var cursor *githubv4.String
+	for {
+		q := listExternalIdentitiesQuery{}
+		err := graphqlClient.Query(ctx, &q, map[string]interface{}{
+			"orgLoginName": githubv4.String(orgName),
+			"pageSize":     githubv4.Int(externalIdentitiesPageSize),
+			"cursor":       cursor,
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("github-connector: failed to list external identities of %s: %w", orgName, err)
+		}
+
+		identities := q.Organization.SamlIdentityProvider.ExternalIdentities
+		for _, edge := range identities.Edges {
+			// Identities that aren't linked to a GitHub account yet have no user.
+			if edge.Node.User.Login == "" {
+				continue
+			}
+
+			identity := &externalIdentity{
+				guid:         edge.Node.Guid,
+				nameID:       edge.Node.SamlIdentity.NameId,
+				scimUserName: edge.Node.ScimIdentity.Username,
+			}
+			for _, email := range edge.Node.SamlIdentity.Emails {
+				identity.samlEmails = append(identity.samlEmails, email.Value)
+			}
+			for _, email := range edge.Node.ScimIdentity.Emails {
+				if email.Primary {
+					identity.scimEmails = append([]string{email.Value}, identity.scimEmails...)
+				} else {
+					identity.scimEmails = append(identity.scimEmails, email.Value)
+				}
+			}
+			rv[strings.ToLower(edge.Node.User.Login)] = identity
+		}
+
+		rateLimit = &v2.RateLimitDescription{
+			Limit:     int64(q.RateLimit.Limit),
+			Remaining: int64(q.RateLimit.Remaining),
+			ResetAt:   timestamppb.New(q.RateLimit.ResetAt.Time),
+		}
+
+		if !identities.PageInfo.HasNextPage {
+			return rv, rateLimit, nil
+		}
+		cursor = githubv4.NewString(identities.PageInfo.EndCursor)
+	}
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,166 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/google/go-github/v63/github"
+	"github.com/shurcooL/githubv4"
+)
+
+const (
+	scimUserSchema      = "urn:ietf:params:scim:schemas:core:2.0:User"
+	scimPatchOpSchema   = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	scimContentType     = "application/scim+json"
+	scimDefaultUserRole = "user"
+)
+
+type scimName struct {
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+	Formatted  string `json:"formatted,omitempty"`
+}
+
+type scimEmail struct {
+	Value   string `json:"value"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary"`
+}
+
+type scimRole struct {
+	Value   string `json:"value"`
+	Primary bool   `json:"primary"`
+}
+
+// scimUser is a user of an enterprise with managed users, as provisioned through the SCIM API.
+type scimUser struct {
+	Schemas     []string    `json:"schemas"`
+	ID          string      `json:"id,omitempty"`
+	ExternalID  string      `json:"externalId"`
+	UserName    string      `json:"userName"`
+	DisplayName string      `json:"displayName,omitempty"`
+	Name        scimName    `json:"name"`
+	Emails      []scimEmail `json:"emails"`
+	Roles       []scimRole  `json:"roles,omitempty"`
+	Active      bool        `json:"active"`
+}
+
+type scimPatchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path,omitempty"`
+	Value interface{} `json:"value"`
+}
+
+type scimPatch struct {
+	Schemas    []string             `json:"schemas"`
+	Operations []scimPatchOperation `json:"Operations"`
+}
+
+// enterpriseExternalIdentityQuery looks up the SCIM identity of a managed user, by either their GitHub login or their
+// SCIM user name. The enterprise is linked to either a SAML or an OIDC identity provider.
+type enterpriseExternalIdentityQuery struct {
+	Enterprise struct {
+		OwnerInfo struct {
+			SamlIdentityProvider struct {
+				ExternalIdentities externalIdentities `graphql:"externalIdentities(first: 1, login: $login, userName: $userName)"`
+			}
+			OidcProvider struct {
+				ExternalIdentities externalIdentities `graphql:"externalIdentities(first: 1, login: $login, userName: $userName)"`
+			}
+		}
+	} `graphql:"enterprise(slug: $enterpriseSlug)"`
+}
+
+type externalIdentities struct {
+	Nodes []struct {
+		Guid string
+		User struct {
+			DatabaseId int64
+			Login      string
+		}
+	}
+}
+
+// scimRequest sends a request to the SCIM API of the enterprise. go-github doesn't support the enterprise SCIM API.
+func scimRequest(ctx context.Context, client *github.Client, method string, path string, body interface{}, v interface{}) error {
+	req, err := client.NewRequest(method, path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", scimContentType)
+	if body != nil {
+		req.Header.Set("Content-Type", scimContentType)
+	}
+
+	_, err = client.Do(ctx, req, v)
+	return err
+}
+
+// createSCIMUser provisions a managed user in the enterprise.
+func createSCIMUser(ctx context.Context, client *github.Client, enterprise string, user *scimUser) (*scimUser, error) {
+	created := &scimUser{}
+	err := scimRequest(ctx, client, http.MethodPost, fmt.Sprintf("scim/v2/enterprises/%s/Users", enterprise), user, created)
+	if err != nil {
+		return nil, fmt.Errorf("github-connectorv2: failed to provision managed user %s: %w", user.UserName, err)
+	}
+
+	return created, nil
+}
+
+// suspendSCIMUser suspends a managed user of the enterprise. Suspended users can't sign in, and are unsuspended by
+// setting them active again.
+func suspendSCIMUser(ctx context.Context, client *github.Client, enterprise string, scimUserID string) error {
+	patch := &scimPatch{
+		Schemas: []string{scimPatchOpSchema},
+		Operations: []scimPatchOperation{
+			{Op: "replace", Path: "active", Value: false},
+		},
+	}
+
+	err := scimRequest(ctx, client, http.MethodPatch, fmt.Sprintf("scim/v2/enterprises/%s/Users/%s", enterprise, scimUserID), patch, nil)
+	if err != nil {
+		return fmt.Errorf("github-connectorv2: failed to suspend managed user: %w", err)
+	}
+
+	return nil
+}
+
+// findExternalIdentity returns the SCIM user ID and GitHub user of a managed user, looked up by either the login or the
+// SCIM user name. It returns an empty SCIM user ID if the user wasn't provisioned through SCIM.
+func findExternalIdentity(
+	ctx context.Context,
+	graphqlClient *githubv4.Client,
+	enterprise string,
+	login string,
+	userName string,
+) (string, int64, error) {
+	var loginVar, userNameVar *githubv4.String
+	if login != "" {
+		loginVar = githubv4.NewString(githubv4.String(login))
+	}
+	if userName != "" {
+		userNameVar = githubv4.NewString(githubv4.String(userName))
+	}
+
+	q := enterpriseExternalIdentityQuery{}
+	err := graphqlClient.Query(ctx, &q, map[string]interface{}{
+		"enterpriseSlug": githubv4.String(enterprise),
+		"login":          loginVar,
+		"userName":       userNameVar,
+	})
+	if err != nil {
+		return "", 0, fmt.Errorf("github-connector: failed to get external identity: %w", err)
+	}
+
+	for _, identities := range []externalIdentities{
+		q.Enterprise.OwnerInfo.SamlIdentityProvider.ExternalIdentities,
+		q.Enterprise.OwnerInfo.OidcProvider.ExternalIdentities,
+	} {
+		if len(identities.Nodes) > 0 {
+			return identities.Nodes[0].Guid, identities.Nodes[0].User.DatabaseId, nil
+		}
+	}
+
+	return "", 0, nil
+}
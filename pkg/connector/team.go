@@ -2,9 +2,12 @@ package connector
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"strconv"
 	"strings"
+	"sync"
 
 	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
 	"github.com/conductorone/baton-sdk/pkg/annotations"
@@ -22,19 +25,37 @@ const (
 	teamRoleMaintainer = "maintainer"
 )
 
+// teamIDPGroup is the entitlement of the IdP groups that the team's membership is synchronized with.
+const teamIDPGroup = "idp_group"
+
+// teamChildTeams and teamIDPGroups are the page states of the child teams and IdP groups of a team.
+const (
+	teamChildTeams = "child_teams"
+	teamIDPGroups  = "idp_groups"
+)
+
 var teamAccessLevels = []string{
 	teamRoleMember,
 	teamRoleMaintainer,
 }
 
-// teamResource creates a new connector resource for a GitHub Team. It is possible that the team has a parent resource.
-func teamResource(team *github.Team, parentResourceID *v2.ResourceId) (*v2.Resource, error) {
+type teamResourceOption func(profile map[string]interface{})
+
+// teamResource creates a new connector resource for a GitHub Team. Teams are always children of the org, nested teams
+// record their parent team in the profile instead.
+func teamResource(team *github.Team, parentResourceID *v2.ResourceId, opts ...teamResourceOption) (*v2.Resource, error) {
 	profile := map[string]interface{}{
 		"members_count": team.GetMembersCount(),
 		"repos_count":   team.GetReposCount(),
 		// Store the org ID in the profile so that we can reference it when calculating grants
 		"orgID": team.GetOrganization().GetID(),
 	}
+	if team.Parent != nil {
+		profile["parent_team_id"] = team.Parent.GetID()
+	}
+	for _, opt := range opts {
+		opt(profile)
+	}
 
 	ret, err := rType.NewGroupResource(
 		team.GetName(),
@@ -54,10 +75,26 @@ func teamResource(team *github.Team, parentResourceID *v2.ResourceId) (*v2.Resou
 	return ret, nil
 }
 
+// teamMembershipExpandable returns an annotation that expands a grant to the team onto every member of the team.
+func teamMembershipExpandable(team *v2.Resource) *v2.GrantExpandable {
+	entitlementIDs := make([]string, 0, len(teamAccessLevels))
+	for _, level := range teamAccessLevels {
+		entitlementIDs = append(entitlementIDs, entitlement.NewEntitlementID(team, level))
+	}
+
+	return &v2.GrantExpandable{
+		EntitlementIds: entitlementIDs,
+	}
+}
+
 type teamResourceType struct {
 	resourceType *v2.ResourceType
 	client       *github.Client
 	orgCache     *orgNameCache
+
+	// noTeamSyncMtx guards noTeamSync, the orgs without team synchronization.
+	noTeamSyncMtx sync.Mutex
+	noTeamSync    map[string]struct{}
 }
 
 func (o *teamResourceType) ResourceType(_ context.Context) *v2.ResourceType {
@@ -91,7 +128,12 @@ func (o *teamResourceType) List(ctx context.Context, parentID *v2.ResourceId, pt
 		return nil, "", nil, err
 	}
 
-	teams, resp, err := o.client.Teams.ListTeams(ctx, orgName, opts)
+	client, err := o.orgCache.GetClient(parentID)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	teams, resp, err := client.Teams.ListTeams(ctx, orgName, opts)
 	if err != nil {
 		return nil, "", nil, fmt.Errorf("github-connector: failed to list teams: %w", err)
 	}
@@ -102,12 +144,21 @@ func (o *teamResourceType) List(ctx context.Context, parentID *v2.ResourceId, pt
 	}
 
 	for _, team := range teams {
-		fullTeam, _, err := o.client.Teams.GetTeamByID(ctx, orgID, team.GetID())
+		fullTeam, _, err := client.Teams.GetTeamByID(ctx, orgID, team.GetID())
+		if err != nil {
+			return nil, "", nil, err
+		}
+
+		var opts []teamResourceOption
+		idpGroups, err := o.idpGroups(ctx, client, orgName, fullTeam.GetSlug())
 		if err != nil {
 			return nil, "", nil, err
 		}
+		if idpGroups != nil {
+			opts = append(opts, withIDPGroups(idpGroups))
+		}
 
-		tr, err := teamResource(fullTeam, &v2.ResourceId{ResourceType: resourceTypeOrg.Id, Resource: fmt.Sprintf("%d", orgID)})
+		tr, err := teamResource(fullTeam, &v2.ResourceId{ResourceType: resourceTypeOrg.Id, Resource: fmt.Sprintf("%d", orgID)}, opts...)
 		if err != nil {
 			return nil, "", nil, err
 		}
@@ -126,6 +177,12 @@ func (o *teamResourceType) List(ctx context.Context, parentID *v2.ResourceId, pt
 func (o *teamResourceType) Entitlements(_ context.Context, resource *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
 	rv := make([]*v2.Entitlement, 0, len(teamAccessLevels))
 	for _, level := range teamAccessLevels {
+		// Child teams are members of their parent team.
+		grantableTo := []*v2.ResourceType{resourceTypeUser}
+		if level == teamRoleMember {
+			grantableTo = append(grantableTo, resourceTypeTeam)
+		}
+
 		rv = append(
 			rv,
 			entitlement.NewPermissionEntitlement(
@@ -138,11 +195,22 @@ func (o *teamResourceType) Entitlements(_ context.Context, resource *v2.Resource
 				),
 				entitlement.WithDisplayName(fmt.Sprintf("%s Team %s", resource.DisplayName, titleCase(level))),
 				entitlement.WithDescription(fmt.Sprintf("Access to %s team in GitHub", resource.DisplayName)),
-				entitlement.WithGrantableTo(resourceTypeUser),
+				entitlement.WithGrantableTo(grantableTo...),
 			),
 		)
 	}
 
+	rv = append(
+		rv,
+		entitlement.NewAssignmentEntitlement(
+			resource,
+			teamIDPGroup,
+			entitlement.WithDisplayName(fmt.Sprintf("%s Team IdP Group", resource.DisplayName)),
+			entitlement.WithDescription(fmt.Sprintf("IdP groups whose members are synchronized to the %s team in GitHub", resource.DisplayName)),
+			entitlement.WithGrantableTo(resourceTypeIDPGroup),
+		),
+	)
+
 	return rv, "", nil, nil
 }
 
@@ -162,52 +230,134 @@ func (o *teamResourceType) Grants(ctx context.Context, resource *v2.Resource, pT
 		return nil, "", nil, fmt.Errorf("error fetching orgID from team profile")
 	}
 
-	org, _, err := o.client.Organizations.GetByID(ctx, orgID)
+	client, err := o.orgCache.GetClient(&v2.ResourceId{ResourceType: resourceTypeOrg.Id, Resource: strconv.FormatInt(orgID, 10)})
 	if err != nil {
 		return nil, "", nil, err
 	}
 
-	githubID, err := parseResourceToGitHub(resource.Id)
+	org, _, err := client.Organizations.GetByID(ctx, orgID)
 	if err != nil {
 		return nil, "", nil, err
 	}
 
-	opts := github.TeamListTeamMembersOptions{
-		ListOptions: github.ListOptions{Page: page},
-	}
-
-	users, resp, err := o.client.Teams.ListTeamMembersByID(ctx, org.GetID(), githubID, &opts)
-	if err != nil {
-		return nil, "", nil, fmt.Errorf("github-connectorv2: failed to fetch team members: %w", err)
-	}
-
-	nextPage, reqAnnos, err := parseResp(resp)
-	if err != nil {
-		return nil, "", nil, fmt.Errorf("github-connectorv2: failed to parse response: %w", err)
-	}
-
-	pageToken, err := bag.NextToken(nextPage)
+	githubID, err := parseResourceToGitHub(resource.Id)
 	if err != nil {
 		return nil, "", nil, err
 	}
 
 	var rv []*v2.Grant
-	for _, user := range users {
-		membership, _, err := o.client.Teams.GetTeamMembershipByID(ctx, org.GetID(), githubID, user.GetLogin())
+	var reqAnnos annotations.Annotations
+
+	switch bag.ResourceTypeID() {
+	case resourceTypeTeam.Id:
+		bag.Pop()
+		bag.Push(pagination.PageState{
+			ResourceTypeID: teamIDPGroups,
+		})
+		bag.Push(pagination.PageState{
+			ResourceTypeID: teamChildTeams,
+		})
+		bag.Push(pagination.PageState{
+			ResourceTypeID: resourceTypeUser.Id,
+		})
+
+	case resourceTypeUser.Id:
+		opts := github.TeamListTeamMembersOptions{
+			ListOptions: github.ListOptions{Page: page},
+		}
+
+		users, resp, err := client.Teams.ListTeamMembersByID(ctx, org.GetID(), githubID, &opts)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("github-connectorv2: failed to fetch team members: %w", err)
+		}
+
+		nextPage, respAnnos, err := parseResp(resp)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("github-connectorv2: failed to parse response: %w", err)
+		}
+		reqAnnos = respAnnos
+
+		err = bag.Next(nextPage)
+		if err != nil {
+			return nil, "", nil, err
+		}
+
+		for _, user := range users {
+			membership, _, err := client.Teams.GetTeamMembershipByID(ctx, org.GetID(), githubID, user.GetLogin())
+			if err != nil {
+				return nil, "", nil, fmt.Errorf("github-connectorv2: failed to get team membership for user: %w", err)
+			}
+
+			ur, err := userResource(ctx, user, user.GetEmail(), nil)
+			if err != nil {
+				return nil, "", nil, err
+			}
+
+			rv = append(rv, grant.NewGrant(resource, membership.GetRole(), ur.Id,
+				grant.WithAnnotation(&v2.V1Identifier{
+					Id: fmt.Sprintf("team-grant:%s:%d:%s", resource.Id.Resource, user.GetID(), membership.GetRole()),
+				}),
+			))
+		}
+
+	case teamChildTeams:
+		childTeams, resp, err := client.Teams.ListChildTeamsByParentID(ctx, org.GetID(), githubID, &github.ListOptions{Page: page})
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("github-connectorv2: failed to fetch child teams: %w", err)
+		}
+
+		nextPage, respAnnos, err := parseResp(resp)
 		if err != nil {
-			return nil, "", nil, fmt.Errorf("github-connectorv2: failed to get team membership for user: %w", err)
+			return nil, "", nil, fmt.Errorf("github-connectorv2: failed to parse response: %w", err)
 		}
+		reqAnnos = respAnnos
 
-		ur, err := userResource(ctx, user, user.GetEmail(), nil)
+		err = bag.Next(nextPage)
 		if err != nil {
 			return nil, "", nil, err
 		}
 
-		rv = append(rv, grant.NewGrant(resource, membership.GetRole(), ur.Id,
-			grant.WithAnnotation(&v2.V1Identifier{
-				Id: fmt.Sprintf("team-grant:%s:%d:%s", resource.Id.Resource, user.GetID(), membership.GetRole()),
-			}),
-		))
+		// Members of child teams inherit the access of the parent team, so the child team's memberships are expanded
+		// onto the parent's member entitlement.
+		for _, childTeam := range childTeams {
+			ctr, err := teamResource(childTeam, resource.ParentResourceId)
+			if err != nil {
+				return nil, "", nil, err
+			}
+
+			rv = append(rv, grant.NewGrant(resource, teamRoleMember, ctr.Id,
+				grant.WithAnnotation(
+					&v2.V1Identifier{
+						Id: fmt.Sprintf("team-grant:%s:%d:%s", resource.Id.Resource, childTeam.GetID(), teamRoleMember),
+					},
+					teamMembershipExpandable(ctr),
+				),
+			))
+		}
+
+	case teamIDPGroups:
+		bag.Pop()
+
+		idpGroups, err := idpGroupsFromTeamProfile(resource)
+		if err != nil {
+			return nil, "", nil, err
+		}
+
+		for _, idpGroup := range idpGroups {
+			gr, err := idpGroupResource(idpGroup, resource.ParentResourceId)
+			if err != nil {
+				return nil, "", nil, err
+			}
+			rv = append(rv, grant.NewGrant(resource, teamIDPGroup, gr.Id))
+		}
+
+	default:
+		return nil, "", nil, fmt.Errorf("unexpected resource type while fetching grants for team")
+	}
+
+	pageToken, err := bag.Marshal()
+	if err != nil {
+		return nil, "", nil, err
 	}
 
 	return rv, pageToken, reqAnnos, nil
@@ -216,13 +366,14 @@ func (o *teamResourceType) Grants(ctx context.Context, resource *v2.Resource, pT
 func (o *teamResourceType) Grant(ctx context.Context, principal *v2.Resource, entitlement *v2.Entitlement) (annotations.Annotations, error) {
 	l := ctxzap.Extract(ctx)
 
-	if principal.Id.ResourceType != resourceTypeUser.Id {
+	if principal.Id.ResourceType != resourceTypeUser.Id && principal.Id.ResourceType != resourceTypeTeam.Id &&
+		principal.Id.ResourceType != resourceTypeIDPGroup.Id {
 		l.Warn(
-			"github-connectorv2: only users can be granted team membership",
+			"github-connectorv2: only users, teams and IdP groups can be granted team entitlements",
 			zap.String("principal_type", principal.Id.ResourceType),
 			zap.String("principal_id", principal.Id.Resource),
 		)
-		return nil, fmt.Errorf("github-connectorv2: only users can be granted team membership")
+		return nil, fmt.Errorf("github-connectorv2: only users, teams and IdP groups can be granted team entitlements")
 	}
 
 	teamId, err := strconv.ParseInt(entitlement.Resource.Id.Resource, 10, 64)
@@ -234,8 +385,8 @@ func (o *teamResourceType) Grant(ctx context.Context, principal *v2.Resource, en
 		return nil, fmt.Errorf("github-connectorv2: parent resource is required to grant team membership")
 	}
 
-	// FIXME(jirwin): Now that we've flattened out the team hierarchy, we don't need to check the parent type.
-	// Leaving this check here for backwards compatability with the old model.
+	// Teams are always children of the org, nested teams record their parent team in the profile. Teams whose parent
+	// resource is a team are from the old model, where the team hierarchy was part of the resource hierarchy.
 	var orgId int64
 	if entitlement.Resource.ParentResourceId.ResourceType == resourceTypeOrg.Id {
 		var err error
@@ -257,23 +408,48 @@ func (o *teamResourceType) Grant(ctx context.Context, principal *v2.Resource, en
 		orgId = orgID
 	}
 
+	client, err := o.orgCache.GetClient(&v2.ResourceId{ResourceType: resourceTypeOrg.Id, Resource: strconv.FormatInt(orgId, 10)})
+	if err != nil {
+		return nil, err
+	}
+
+	permission, err := teamEntitlementSlug(entitlement)
+	if err != nil {
+		return nil, err
+	}
+
+	// Granting the IdP group entitlement connects the IdP group to the team for team synchronization.
+	if (principal.Id.ResourceType == resourceTypeIDPGroup.Id) != (permission == teamIDPGroup) {
+		return nil, fmt.Errorf("github-connectorv2: only IdP groups can be granted the IdP group entitlement of a team")
+	}
+	if principal.Id.ResourceType == resourceTypeIDPGroup.Id {
+		return nil, setTeamIDPGroup(ctx, client, orgId, teamId, idpGroupFromResource(principal), true)
+	}
+
 	userId, err := strconv.ParseInt(principal.Id.Resource, 10, 64)
 	if err != nil {
 		return nil, err
 	}
 
-	user, _, err := o.client.Users.GetByID(ctx, userId)
+	// Granting team membership to a team makes it a child team.
+	if principal.Id.ResourceType == resourceTypeTeam.Id {
+		if permission != teamRoleMember {
+			return nil, fmt.Errorf("github-connectorv2: teams can only be granted team membership")
+		}
+		return nil, setParentTeam(ctx, client, orgId, userId, &teamId)
+	}
+
+	err = checkTeamNotSynced(ctx, client, orgId, teamId)
 	if err != nil {
-		return nil, fmt.Errorf("github-connectorv2: failed to get user %d, err: %w", userId, err)
+		return nil, err
 	}
 
-	enIDParts := strings.Split(entitlement.Id, ":")
-	if len(enIDParts) != 3 {
-		return nil, fmt.Errorf("github-connectorv2: invalid entitlement ID: %s", entitlement.Id)
+	user, _, err := client.Users.GetByID(ctx, userId)
+	if err != nil {
+		return nil, fmt.Errorf("github-connectorv2: failed to get user %d, err: %w", userId, err)
 	}
-	permission := enIDParts[2]
 
-	_, _, e := o.client.Teams.AddTeamMembershipByID(
+	_, _, e := client.Teams.AddTeamMembershipByID(
 		ctx,
 		orgId,
 		teamId,
@@ -294,13 +470,14 @@ func (o *teamResourceType) Revoke(ctx context.Context, grant *v2.Grant) (annotat
 	entitlement := grant.Entitlement
 	principal := grant.Principal
 
-	if principal.Id.ResourceType != resourceTypeUser.Id {
+	if principal.Id.ResourceType != resourceTypeUser.Id && principal.Id.ResourceType != resourceTypeTeam.Id &&
+		principal.Id.ResourceType != resourceTypeIDPGroup.Id {
 		l.Warn(
-			"github-connectorv2: only users can have team membership revoked",
+			"github-connectorv2: only users, teams and IdP groups can have team entitlements revoked",
 			zap.String("principal_type", principal.Id.ResourceType),
 			zap.String("principal_id", principal.Id.Resource),
 		)
-		return nil, fmt.Errorf("github-connectorv2: only users can have team membership revoked")
+		return nil, fmt.Errorf("github-connectorv2: only users, teams and IdP groups can have team entitlements revoked")
 	}
 
 	teamId, err := strconv.ParseInt(entitlement.Resource.Id.Resource, 10, 64)
@@ -317,16 +494,41 @@ func (o *teamResourceType) Revoke(ctx context.Context, grant *v2.Grant) (annotat
 		return nil, err
 	}
 
+	client, err := o.orgCache.GetClient(entitlement.Resource.ParentResourceId)
+	if err != nil {
+		return nil, err
+	}
+
+	if principal.Id.ResourceType == resourceTypeIDPGroup.Id {
+		return nil, setTeamIDPGroup(ctx, client, orgId, teamId, idpGroupFromResource(principal), false)
+	}
+
 	userId, err := strconv.ParseInt(principal.Id.Resource, 10, 64)
 	if err != nil {
 		return nil, err
 	}
 
-	user, _, err := o.client.Users.GetByID(ctx, userId)
+	if principal.Id.ResourceType == resourceTypeTeam.Id {
+		permission, err := teamEntitlementSlug(entitlement)
+		if err != nil {
+			return nil, err
+		}
+		if permission != teamRoleMember {
+			return nil, fmt.Errorf("github-connectorv2: teams can only have team membership revoked")
+		}
+		return nil, unsetParentTeam(ctx, client, orgId, userId, teamId)
+	}
+
+	err = checkTeamNotSynced(ctx, client, orgId, teamId)
+	if err != nil {
+		return nil, err
+	}
+
+	user, _, err := client.Users.GetByID(ctx, userId)
 	if err != nil {
 		return nil, fmt.Errorf("github-connectorv2: failed to get user %d, err: %w", userId, err)
 	}
-	_, e := o.client.Teams.RemoveTeamMembershipByID(ctx, orgId, teamId, user.GetLogin())
+	_, e := client.Teams.RemoveTeamMembershipByID(ctx, orgId, teamId, user.GetLogin())
 	if e != nil {
 		return nil, fmt.Errorf("github-connectorv2: failed to revoke user team membership: %w", e)
 	}
@@ -334,10 +536,256 @@ func (o *teamResourceType) Revoke(ctx context.Context, grant *v2.Grant) (annotat
 	return nil, nil
 }
 
+// idpGroups returns the IdP groups that the team's membership is synchronized with, or nil if the org doesn't have team
+// synchronization.
+func (o *teamResourceType) idpGroups(ctx context.Context, client *github.Client, orgName string, teamSlug string) ([]*github.IDPGroup, error) {
+	o.noTeamSyncMtx.Lock()
+	_, noTeamSync := o.noTeamSync[orgName]
+	o.noTeamSyncMtx.Unlock()
+	if noTeamSync {
+		return nil, nil
+	}
+
+	groups, err := listTeamIDPGroups(ctx, client, orgName, teamSlug)
+	if err != nil {
+		if errors.Is(err, errTeamSyncUnavailable) {
+			o.noTeamSyncMtx.Lock()
+			o.noTeamSync[orgName] = struct{}{}
+			o.noTeamSyncMtx.Unlock()
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return groups, nil
+}
+
+// checkTeamNotSynced returns an error if the team's membership is synchronized with IdP groups, because GitHub reverts
+// any change to the membership that isn't made in the identity provider.
+func checkTeamNotSynced(ctx context.Context, client *github.Client, orgID int64, teamID int64) error {
+	team, _, err := client.Teams.GetTeamByID(ctx, orgID, teamID)
+	if err != nil {
+		return fmt.Errorf("github-connectorv2: failed to get team %d: %w", teamID, err)
+	}
+
+	groups, err := listTeamIDPGroups(ctx, client, team.GetOrganization().GetLogin(), team.GetSlug())
+	if err != nil {
+		if errors.Is(err, errTeamSyncUnavailable) {
+			return nil
+		}
+		return err
+	}
+
+	if len(groups) > 0 {
+		names := make([]string, 0, len(groups))
+		for _, group := range groups {
+			names = append(names, group.GetGroupName())
+		}
+		return fmt.Errorf(
+			"github-connectorv2: the membership of team %s is synchronized with the IdP groups %s and can only be changed in the identity provider",
+			team.GetSlug(),
+			strings.Join(names, ", "),
+		)
+	}
+
+	return nil
+}
+
+// setTeamIDPGroup connects the IdP group to the team for team synchronization, or disconnects it. GitHub replaces all
+// the IdP groups of a team at once, so the group is added to or removed from the current groups.
+func setTeamIDPGroup(ctx context.Context, client *github.Client, orgID int64, teamID int64, group *github.IDPGroup, connect bool) error {
+	team, _, err := client.Teams.GetTeamByID(ctx, orgID, teamID)
+	if err != nil {
+		return fmt.Errorf("github-connectorv2: failed to get team %d: %w", teamID, err)
+	}
+	orgName := team.GetOrganization().GetLogin()
+
+	groups, err := listTeamIDPGroups(ctx, client, orgName, team.GetSlug())
+	if err != nil {
+		return err
+	}
+
+	connected := false
+	updated := make([]*github.IDPGroup, 0, len(groups)+1)
+	for _, g := range groups {
+		if g.GetGroupID() == group.GetGroupID() {
+			connected = true
+			continue
+		}
+		updated = append(updated, g)
+	}
+	if connected == connect {
+		return nil
+	}
+	if connect {
+		updated = append(updated, group)
+	}
+
+	_, _, err = client.Teams.CreateOrUpdateIDPGroupConnectionsBySlug(ctx, orgName, team.GetSlug(), github.IDPGroupList{Groups: updated})
+	if err != nil {
+		return fmt.Errorf("github-connectorv2: failed to update IdP groups of team %s: %w", team.GetSlug(), err)
+	}
+
+	return nil
+}
+
+// unsetParentTeam moves a child team to the top level of the org, unless it has moved under another parent since.
+func unsetParentTeam(ctx context.Context, client *github.Client, orgID int64, teamID int64, parentTeamID int64) error {
+	team, _, err := client.Teams.GetTeamByID(ctx, orgID, teamID)
+	if err != nil {
+		return fmt.Errorf("github-connectorv2: failed to get team %d: %w", teamID, err)
+	}
+
+	if team.GetParent().GetID() != parentTeamID {
+		ctxzap.Extract(ctx).Info(
+			"github-connectorv2: team is no longer a child of the parent team, nothing to revoke",
+			zap.Int64("team_id", teamID),
+			zap.Int64("parent_team_id", parentTeamID),
+		)
+		return nil
+	}
+
+	return setParentTeam(ctx, client, orgID, teamID, nil)
+}
+
+// teamEntitlementSlug returns the slug of a team entitlement, such as member or maintainer.
+func teamEntitlementSlug(entitlement *v2.Entitlement) (string, error) {
+	enIDParts := strings.Split(entitlement.Id, ":")
+	if len(enIDParts) != 3 {
+		return "", fmt.Errorf("github-connectorv2: invalid entitlement ID: %s", entitlement.Id)
+	}
+
+	return enIDParts[2], nil
+}
+
+// setParentTeam moves a team under the parent team, or to the top level of the org if the parent team is nil.
+func setParentTeam(ctx context.Context, client *github.Client, orgID int64, teamID int64, parentTeamID *int64) error {
+	team, _, err := client.Teams.GetTeamByID(ctx, orgID, teamID)
+	if err != nil {
+		return fmt.Errorf("github-connectorv2: failed to get team %d: %w", teamID, err)
+	}
+
+	_, _, err = client.Teams.EditTeamByID(ctx, orgID, teamID, github.NewTeam{
+		Name:         team.GetName(),
+		ParentTeamID: parentTeamID,
+	}, parentTeamID == nil)
+	if err != nil {
+		return fmt.Errorf("github-connectorv2: failed to update parent team: %w", err)
+	}
+
+	return nil
+}
+
+// Create creates a team in the org of the resource's parent. The description, privacy and parent team can be set in
+// the team's profile.
+func (o *teamResourceType) Create(ctx context.Context, resource *v2.Resource) (*v2.Resource, annotations.Annotations, error) {
+	if resource.GetParentResourceId() == nil || resource.ParentResourceId.ResourceType != resourceTypeOrg.Id {
+		return nil, nil, fmt.Errorf("github-connectorv2: an org parent resource is required to create a team")
+	}
+
+	if resource.GetDisplayName() == "" {
+		return nil, nil, fmt.Errorf("github-connectorv2: a name is required to create a team")
+	}
+
+	newTeam := github.NewTeam{
+		Name: resource.GetDisplayName(),
+	}
+	if resource.GetDescription() != "" {
+		newTeam.Description = github.String(resource.GetDescription())
+	}
+
+	groupTrait, err := rType.GetGroupTrait(resource)
+	if err == nil {
+		if description, ok := rType.GetProfileStringValue(groupTrait.Profile, "description"); ok && newTeam.Description == nil {
+			newTeam.Description = github.String(description)
+		}
+		if privacy, ok := rType.GetProfileStringValue(groupTrait.Profile, "privacy"); ok {
+			newTeam.Privacy = github.String(privacy)
+		}
+		if parentTeamID, ok := rType.GetProfileInt64Value(groupTrait.Profile, "parent_team_id"); ok {
+			newTeam.ParentTeamID = github.Int64(parentTeamID)
+		}
+	}
+
+	orgName, err := o.orgCache.GetOrgName(ctx, resource.ParentResourceId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	client, err := o.orgCache.GetClient(resource.ParentResourceId)
+	if err != nil {
+		return nil, nil, err
+	}
+	team, resp, err := client.Teams.CreateTeam(ctx, orgName, newTeam)
+	if err != nil {
+		return nil, nil, fmt.Errorf("github-connectorv2: failed to create team: %w", err)
+	}
+
+	_, reqAnnos, err := parseResp(resp)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tr, err := teamResource(team, resource.ParentResourceId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return tr, reqAnnos, nil
+}
+
+// Delete deletes a team. Only the team ID is known, so the team is looked up in each of the orgs.
+func (o *teamResourceType) Delete(ctx context.Context, resourceId *v2.ResourceId) (annotations.Annotations, error) {
+	teamID, err := strconv.ParseInt(resourceId.Resource, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	orgIDs, err := o.orgCache.OrgIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, orgID := range orgIDs {
+		githubOrgID, err := parseResourceToGitHub(orgID)
+		if err != nil {
+			return nil, err
+		}
+
+		client, err := o.orgCache.GetClient(orgID)
+		if err != nil {
+			return nil, err
+		}
+		_, resp, err := client.Teams.GetTeamByID(ctx, githubOrgID, teamID)
+		if err != nil {
+			// The team is in another org, or the credentials can't read this org's teams.
+			if resp != nil && (resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusForbidden) {
+				continue
+			}
+			return nil, fmt.Errorf("github-connectorv2: failed to get team: %w", err)
+		}
+
+		resp, err = client.Teams.DeleteTeamByID(ctx, githubOrgID, teamID)
+		if err != nil {
+			return nil, fmt.Errorf("github-connectorv2: failed to delete team: %w", err)
+		}
+
+		_, reqAnnos, err := parseResp(resp)
+		if err != nil {
+			return nil, err
+		}
+
+		return reqAnnos, nil
+	}
+
+	return nil, fmt.Errorf("github-connectorv2: team %d not found", teamID)
+}
+
 func teamBuilder(client *github.Client, orgCache *orgNameCache) *teamResourceType {
 	return &teamResourceType{
 		resourceType: resourceTypeTeam,
 		client:       client,
 		orgCache:     orgCache,
+		noTeamSync:   make(map[string]struct{}),
 	}
 }
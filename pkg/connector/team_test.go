@@ -2,11 +2,14 @@ package connector
 
 import (
 	"context"
+	"strconv"
 	"testing"
 
 	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
 	"github.com/conductorone/baton-sdk/pkg/pagination"
 	entitlement2 "github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	rType "github.com/conductorone/baton-sdk/pkg/types/resource"
 	"github.com/google/go-github/v63/github"
 	"github.com/stretchr/testify/require"
 
@@ -39,10 +42,9 @@ func TestTeam(t *testing.T) {
 		require.Nil(t, err)
 		require.Empty(t, grantAnnotations)
 
-		grants, nextToken, grantsAnnotations, err := client.Grants(ctx, team, &pagination.Token{})
-		require.Nil(t, err)
-		test.AssertNoRatelimitAnnotations(t, grantsAnnotations)
-		require.Equal(t, "", nextToken)
+		grants := test.ExhaustPagination(t, func(pToken *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+			return client.Grants(ctx, team, pToken)
+		})
 		require.Len(t, grants, 1)
 
 		grant := v2.Grant{
@@ -54,4 +56,197 @@ func TestTeam(t *testing.T) {
 		require.Nil(t, err)
 		require.Empty(t, revokeAnnotations)
 	})
+
+	t.Run("should create and delete teams", func(t *testing.T) {
+		seed := seedMockGitHub(t)
+
+		client := teamBuilder(seed.client, seed.cache)
+
+		organization, _ := organizationResource(ctx, seed.organization, nil)
+
+		newTeam, err := rType.NewGroupResource(
+			"backend",
+			resourceTypeTeam,
+			"",
+			[]rType.GroupTraitOption{rType.WithGroupProfile(map[string]interface{}{
+				"privacy": "closed",
+			})},
+			rType.WithParentResourceID(organization.Id),
+			rType.WithDescription("The backend team"),
+		)
+		require.Nil(t, err)
+
+		team, _, err := client.Create(ctx, newTeam)
+		require.Nil(t, err)
+		require.Equal(t, "backend", team.DisplayName)
+		require.Equal(t, organization.Id, team.ParentResourceId)
+
+		teamTrait, err := rType.GetGroupTrait(team)
+		require.Nil(t, err)
+		orgID, ok := rType.GetProfileInt64Value(teamTrait.Profile, "orgID")
+		require.True(t, ok)
+		require.Equal(t, seed.organization.GetID(), orgID)
+
+		_, err = client.Delete(ctx, team.Id)
+		require.Nil(t, err)
+
+		_, err = client.Delete(ctx, team.Id)
+		require.NotNil(t, err)
+	})
+
+	t.Run("should expand parent team membership from child teams", func(t *testing.T) {
+		seed := seedMockGitHub(t)
+		githubChildTeam := seed.mgh.AddChildTeam(seed.team.GetID(), 79)
+
+		client := teamBuilder(seed.client, seed.cache)
+
+		organization, _ := organizationResource(ctx, seed.organization, nil)
+		team, _ := teamResource(seed.team, organization.Id)
+		childTeam, _ := teamResource(githubChildTeam, organization.Id)
+
+		childTeamTrait, err := rType.GetGroupTrait(childTeam)
+		require.Nil(t, err)
+		parentTeamID, ok := rType.GetProfileInt64Value(childTeamTrait.Profile, "parent_team_id")
+		require.True(t, ok)
+		require.Equal(t, seed.team.GetID(), parentTeamID)
+
+		grants := test.ExhaustPagination(t, func(pToken *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+			return client.Grants(ctx, team, pToken)
+		})
+		require.Len(t, grants, 2)
+
+		childTeamGrant := grants[1]
+		require.Equal(t, childTeam.Id.Resource, childTeamGrant.Principal.Id.Resource)
+		require.Equal(t, entitlement2.NewEntitlementID(team, teamRoleMember), childTeamGrant.Entitlement.Id)
+
+		expandable := &v2.GrantExpandable{}
+		grantAnnotations := annotations.Annotations(childTeamGrant.Annotations)
+		ok, err = grantAnnotations.Pick(expandable)
+		require.Nil(t, err)
+		require.True(t, ok)
+		require.ElementsMatch(t, []string{
+			entitlement2.NewEntitlementID(childTeam, teamRoleMember),
+			entitlement2.NewEntitlementID(childTeam, teamRoleMaintainer),
+		}, expandable.EntitlementIds)
+
+		// Revoking the membership moves the child team to the top level of the org.
+		_, err = client.Revoke(ctx, childTeamGrant)
+		require.Nil(t, err)
+
+		grants = test.ExhaustPagination(t, func(pToken *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+			return client.Grants(ctx, team, pToken)
+		})
+		require.Len(t, grants, 1)
+
+		_, err = client.Grant(ctx, childTeam, childTeamGrant.Entitlement)
+		require.Nil(t, err)
+
+		grants = test.ExhaustPagination(t, func(pToken *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+			return client.Grants(ctx, team, pToken)
+		})
+		require.Len(t, grants, 2)
+
+		// Teams can't be maintainers of other teams.
+		maintainerGrant := &v2.Grant{
+			Entitlement: &v2.Entitlement{Id: entitlement2.NewEntitlementID(team, teamRoleMaintainer), Resource: team},
+			Principal:   childTeam,
+		}
+		_, err = client.Grant(ctx, childTeam, maintainerGrant.Entitlement)
+		require.NotNil(t, err)
+		_, err = client.Revoke(ctx, maintainerGrant)
+		require.NotNil(t, err)
+
+		// A stale revoke leaves a child team that moved under another parent alone.
+		githubOtherTeam := seed.mgh.AddChildTeam(seed.team.GetID(), 80)
+		otherTeam, _ := teamResource(githubOtherTeam, organization.Id)
+		_, err = client.Grant(ctx, childTeam, &v2.Entitlement{Id: entitlement2.NewEntitlementID(otherTeam, teamRoleMember), Resource: otherTeam})
+		require.Nil(t, err)
+
+		_, err = client.Revoke(ctx, childTeamGrant)
+		require.Nil(t, err)
+
+		grants = test.ExhaustPagination(t, func(pToken *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+			return client.Grants(ctx, otherTeam, pToken)
+		})
+		require.Len(t, grants, 1)
+		require.Equal(t, childTeam.Id.Resource, grants[0].Principal.Id.Resource)
+	})
+	t.Run("should manage the IdP groups of teams with team synchronization", func(t *testing.T) {
+		seed := seedMockGitHub(t)
+		seed.mgh.AddIDPGroup("a1b2", "engineering")
+		seed.mgh.AddIDPGroup("c3d4", "platform")
+		seed.mgh.ConnectIDPGroup(seed.team.GetID(), "a1b2")
+
+		client := teamBuilder(seed.client, seed.cache)
+
+		organization, _ := organizationResource(ctx, seed.organization, nil)
+		user, _ := userResource(ctx, seed.user, *seed.user.Email, nil)
+
+		// The IdP groups of teams are recorded in the team profile when teams are listed.
+		listTeam := func() *v2.Resource {
+			teams := test.ExhaustPagination(t, func(pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+				return client.List(ctx, organization.Id, pToken)
+			})
+			for _, team := range teams {
+				if team.Id.Resource == strconv.FormatInt(seed.team.GetID(), 10) {
+					return team
+				}
+			}
+			require.FailNow(t, "team not found")
+			return nil
+		}
+		team := listTeam()
+
+		idpGroups := test.ExhaustPagination(t, func(pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+			return idpGroupBuilder(seed.client, seed.cache).List(ctx, organization.Id, pToken)
+		})
+		require.Len(t, idpGroups, 2)
+		var platform *v2.Resource
+		for _, idpGroup := range idpGroups {
+			if idpGroup.Id.Resource == "c3d4" {
+				platform = idpGroup
+			}
+		}
+		require.NotNil(t, platform)
+
+		grants := test.ExhaustPagination(t, func(pToken *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+			return client.Grants(ctx, team, pToken)
+		})
+		require.Len(t, grants, 2)
+		require.Equal(t, "a1b2", grants[1].Principal.Id.Resource)
+		require.Equal(t, entitlement2.NewEntitlementID(team, teamIDPGroup), grants[1].Entitlement.Id)
+
+		// The membership of synchronized teams can only be changed in the identity provider.
+		memberEntitlement := &v2.Entitlement{
+			Id:       entitlement2.NewEntitlementID(team, teamRoleMember),
+			Resource: team,
+		}
+		_, err := client.Grant(ctx, user, memberEntitlement)
+		require.ErrorContains(t, err, "synchronized with the IdP groups engineering")
+		_, err = client.Revoke(ctx, &v2.Grant{Entitlement: memberEntitlement, Principal: user})
+		require.ErrorContains(t, err, "synchronized with the IdP groups engineering")
+
+		idpGroupEntitlement := &v2.Entitlement{
+			Id:       entitlement2.NewEntitlementID(team, teamIDPGroup),
+			Resource: team,
+		}
+		_, err = client.Grant(ctx, user, idpGroupEntitlement)
+		require.NotNil(t, err)
+
+		_, err = client.Grant(ctx, platform, idpGroupEntitlement)
+		require.Nil(t, err)
+		team = listTeam()
+		grants = test.ExhaustPagination(t, func(pToken *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+			return client.Grants(ctx, team, pToken)
+		})
+		require.Len(t, grants, 3)
+
+		// Once all its IdP groups are disconnected, the team's membership can be changed again.
+		for _, grant := range grants[1:] {
+			_, err = client.Revoke(ctx, grant)
+			require.Nil(t, err)
+		}
+		_, err = client.Grant(ctx, user, memberEntitlement)
+		require.Nil(t, err)
+	})
 }
@@ -5,22 +5,138 @@ import (
 	"fmt"
 	"net/http"
 	"net/mail"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
 	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/connectorbuilder"
 	"github.com/conductorone/baton-sdk/pkg/pagination"
 	"github.com/conductorone/baton-sdk/pkg/types/resource"
 	"github.com/google/go-github/v63/github"
 	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
 	"github.com/shurcooL/githubv4"
 	"go.uber.org/zap"
-	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
+// Details of the user status, for users that are enabled but aren't in good standing, or are disabled.
+const (
+	userStatusSuspended    = "suspended"
+	userStatusDormant      = "dormant"
+	userStatusSAMLUnlinked = "saml_unlinked"
+)
+
+// userResourceOption sets details on a user resource that aren't available on the github.User itself.
+type userResourceOption func(profile map[string]interface{}) []resource.UserTraitOption
+
+// withOrgUsers sets the orgs the user is an outside collaborator or billing manager of. The user is synced for every
+// org they have access to, so the orgs are looked up in all of them rather than only the org being synced.
+func withOrgUsers(users map[string]*orgUsers, userID int64) userResourceOption {
+	return func(profile map[string]interface{}) []resource.UserTraitOption {
+		var outsideCollaboratorOrgs, billingManagerOrgs []string
+		for orgName, u := range users {
+			if _, ok := u.outsideCollaborators[userID]; ok {
+				outsideCollaboratorOrgs = append(outsideCollaboratorOrgs, orgName)
+			}
+			if u.isBillingManager(userID) {
+				billingManagerOrgs = append(billingManagerOrgs, orgName)
+			}
+		}
+		slices.Sort(outsideCollaboratorOrgs)
+		slices.Sort(billingManagerOrgs)
+
+		profile["outside_collaborator"] = len(outsideCollaboratorOrgs) > 0
+		profile["outside_collaborator_orgs"] = strings.Join(outsideCollaboratorOrgs, ",")
+		profile["billing_manager"] = len(billingManagerOrgs) > 0
+		profile["billing_manager_orgs"] = strings.Join(billingManagerOrgs, ",")
+		return nil
+	}
+}
+
+// withSAMLIdentities marks a user who is a member of an org with SAML single sign-on without having linked a SAML
+// identity. Like withOrgUsers, every synced org with SAML single sign-on is checked rather than only the org being
+// synced.
+func withSAMLIdentities(
+	identities map[string]map[string]*externalIdentity,
+	users map[string]*orgUsers,
+	user *github.User,
+) userResourceOption {
+	return func(profile map[string]interface{}) []resource.UserTraitOption {
+		var samlMember bool
+		var unlinkedOrgs []string
+		for orgName, orgIdentities := range identities {
+			u, ok := users[orgName]
+			if !ok {
+				continue
+			}
+			if _, ok := u.members[user.GetID()]; !ok {
+				continue
+			}
+
+			samlMember = true
+			if _, ok := orgIdentities[strings.ToLower(user.GetLogin())]; !ok {
+				unlinkedOrgs = append(unlinkedOrgs, orgName)
+			}
+		}
+		if !samlMember {
+			return nil
+		}
+		slices.Sort(unlinkedOrgs)
+
+		profile["saml_linked"] = len(unlinkedOrgs) == 0
+		profile["saml_unlinked_orgs"] = strings.Join(unlinkedOrgs, ",")
+		if len(unlinkedOrgs) == 0 {
+			return nil
+		}
+		return []resource.UserTraitOption{
+			resource.WithDetailedStatus(v2.UserTrait_Status_STATUS_ENABLED, userStatusSAMLUnlinked),
+		}
+	}
+}
+
+// withDormant marks a user who has had no activity for the dormant period.
+func withDormant() userResourceOption {
+	return func(profile map[string]interface{}) []resource.UserTraitOption {
+		profile["dormant"] = true
+		return []resource.UserTraitOption{
+			resource.WithDetailedStatus(v2.UserTrait_Status_STATUS_ENABLED, userStatusDormant),
+		}
+	}
+}
+
+// withMFAEnabled sets whether the user has enabled two-factor authentication.
+func withMFAEnabled(enabled bool) userResourceOption {
+	return func(profile map[string]interface{}) []resource.UserTraitOption {
+		return []resource.UserTraitOption{
+			resource.WithMFAStatus(&v2.UserTrait_MFAStatus{MfaEnabled: enabled}),
+		}
+	}
+}
+
+// withActivity sets the last activity and last login of the user.
+func withActivity(activity *userActivity) userResourceOption {
+	return func(profile map[string]interface{}) []resource.UserTraitOption {
+		if activity.lastActivity != nil {
+			profile["last_activity"] = activity.lastActivity.Format(time.RFC3339)
+		}
+		if activity.lastLogin == nil {
+			return nil
+		}
+
+		profile["last_login"] = activity.lastLogin.Format(time.RFC3339)
+		return []resource.UserTraitOption{
+			resource.WithLastLogin(*activity.lastLogin),
+		}
+	}
+}
+
 // Create a new connector resource for a GitHub user.
-func userResource(ctx context.Context, user *github.User, userEmail string, extraEmails []string) (*v2.Resource, error) {
+func userResource(ctx context.Context, user *github.User, userEmail string, extraEmails []string, opts ...userResourceOption) (*v2.Resource, error) {
 	displayName := user.GetName()
 	if displayName == "" {
 		// users do not always specify a name and we only get public email from
@@ -39,15 +155,16 @@ func userResource(ctx context.Context, user *github.User, userEmail string, extr
 	}
 
 	profile := map[string]interface{}{
-		"first_name": firstName,
-		"last_name":  lastName,
-		"login":      user.GetLogin(),
-		"user_id":    strconv.Itoa(int(user.GetID())),
+		"first_name":           firstName,
+		"last_name":            lastName,
+		"login":                user.GetLogin(),
+		"user_id":              strconv.Itoa(int(user.GetID())),
+		"outside_collaborator": false,
+		"billing_manager":      false,
 	}
 
 	userTrait := []resource.UserTraitOption{
 		resource.WithEmail(userEmail, true),
-		resource.WithUserProfile(profile),
 		resource.WithStatus(v2.UserTrait_Status_STATUS_ENABLED),
 	}
 
@@ -69,6 +186,21 @@ func userResource(ctx context.Context, user *github.User, userEmail string, extr
 		}))
 	}
 
+	for _, opt := range opts {
+		userTrait = append(userTrait, opt(profile)...)
+	}
+
+	if user.CreatedAt != nil {
+		userTrait = append(userTrait, resource.WithCreatedAt(user.GetCreatedAt().Time))
+	}
+
+	// Suspended users can't sign in, regardless of the details set by the options.
+	if user.SuspendedAt != nil {
+		profile["suspended_at"] = user.GetSuspendedAt().Format(time.RFC3339)
+		userTrait = append(userTrait, resource.WithDetailedStatus(v2.UserTrait_Status_STATUS_DISABLED, userStatusSuspended))
+	}
+	userTrait = append(userTrait, resource.WithUserProfile(profile))
+
 	ret, err := resource.NewUserResource(
 		displayName,
 		resourceTypeUser,
@@ -86,12 +218,51 @@ func userResource(ctx context.Context, user *github.User, userEmail string, extr
 	return ret, nil
 }
 
+// accountConfig configures how accounts are created and deleted.
+type accountConfig struct {
+	enterprise string
+	// emu is true when the enterprise has managed users, which are provisioned through SCIM instead of being invited.
+	emu bool
+	// orgs are the configured orgs. New accounts are invited to the org in their profile, or to the only configured org.
+	orgs []string
+	// inviteRole is the org role of invited accounts.
+	inviteRole string
+	// inviteTeams are the slugs of the teams invited accounts are added to.
+	inviteTeams []string
+}
+
 type userResourceType struct {
 	resourceType   *v2.ResourceType
 	client         *github.Client
 	graphqlClient  *githubv4.Client
 	hasSAMLEnabled *bool
 	orgCache       *orgNameCache
+	accountConfig  accountConfig
+	activityConfig activityConfig
+
+	// activityMtx guards activityCache, the activity of every user.
+	activityMtx   sync.Mutex
+	activityCache *activityReport
+
+	// externalIdentitiesMtx guards externalIdentitiesCache, the external identities of the members of each org.
+	externalIdentitiesMtx   sync.Mutex
+	externalIdentitiesCache map[string]map[string]*externalIdentity
+
+	// verifiedEmailsMtx guards verifiedEmailsCache, the verified domain emails of the members of each org.
+	verifiedEmailsMtx   sync.Mutex
+	verifiedEmailsCache map[string]map[string][]string
+
+	// twoFactorDisabledMtx guards twoFactorDisabledCache, the users of each org without two-factor authentication, keyed
+	// by org name and role.
+	twoFactorDisabledMtx   sync.Mutex
+	twoFactorDisabledCache map[string]map[int64]struct{}
+	// enterpriseTwoFactorCache is whether each enterprise member has two-factor authentication enabled, which is also
+	// guarded by twoFactorDisabledMtx.
+	enterpriseTwoFactorCache *enterpriseTwoFactor
+
+	// samlEnabledMtx guards samlEnabledCache, whether each org has SAML single sign-on, keyed by org ID.
+	samlEnabledMtx   sync.Mutex
+	samlEnabledCache map[string]bool
 }
 
 func (o *userResourceType) ResourceType(_ context.Context) *v2.ResourceType {
@@ -115,19 +286,79 @@ func (o *userResourceType) List(ctx context.Context, parentID *v2.ResourceId, pt
 		return nil, "", nil, err
 	}
 
-	hasSamlBool, err := o.hasSAML(ctx, orgName)
+	hasSamlBool, err := o.hasSAML(ctx, parentID, orgName)
 	if err != nil {
 		return nil, "", nil, err
 	}
 	var restApiRateLimit *v2.RateLimitDescription
 
-	opts := github.ListMembersOptions{
-		ListOptions: github.ListOptions{Page: page, PerPage: pt.Size},
+	client, err := o.orgCache.GetClient(parentID)
+	if err != nil {
+		return nil, "", nil, err
 	}
-
-	users, resp, err := o.client.Organizations.ListMembers(ctx, orgName, &opts)
+	graphqlClient, err := o.graphqlClientForOrg(parentID)
 	if err != nil {
-		return nil, "", nil, fmt.Errorf("github-connector: ListMembers failed: %w", err)
+		return nil, "", nil, err
+	}
+	listOpts := github.ListOptions{Page: page, PerPage: pt.Size}
+
+	var users []*github.User
+	var resp *github.Response
+	role := bag.ResourceTypeID()
+	switch role {
+	case resourceTypeUser.Id:
+		// External identities and verified domain emails are cached while the users of the org are listed, and
+		// reloaded for every sync.
+		o.externalIdentitiesMtx.Lock()
+		delete(o.externalIdentitiesCache, orgName)
+		o.externalIdentitiesMtx.Unlock()
+		o.verifiedEmailsMtx.Lock()
+		delete(o.verifiedEmailsCache, orgName)
+		o.verifiedEmailsMtx.Unlock()
+		o.twoFactorDisabledMtx.Lock()
+		delete(o.twoFactorDisabledCache, twoFactorDisabledKey(orgName, orgRoleMember))
+		delete(o.twoFactorDisabledCache, twoFactorDisabledKey(orgName, orgRoleOutsideCollaborator))
+		o.twoFactorDisabledMtx.Unlock()
+		o.orgCache.ResetOrgUsers(parentID)
+
+		// Users are listed from the org members, followed by outside collaborators and billing managers who are never
+		// org members.
+		bag.Pop()
+		bag.Push(pagination.PageState{
+			ResourceTypeID: orgRoleBillingManager,
+		})
+		bag.Push(pagination.PageState{
+			ResourceTypeID: orgRoleOutsideCollaborator,
+		})
+		bag.Push(pagination.PageState{
+			ResourceTypeID: orgRoleMember,
+		})
+
+		pageToken, err := bag.Marshal()
+		if err != nil {
+			return nil, "", nil, err
+		}
+		return nil, pageToken, nil, nil
+
+	case orgRoleBillingManager:
+		return o.listBillingManagers(ctx, bag, parentID)
+
+	case orgRoleMember:
+		users, resp, err = client.Organizations.ListMembers(ctx, orgName, &github.ListMembersOptions{ListOptions: listOpts})
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("github-connector: ListMembers failed: %w", err)
+		}
+
+	case orgRoleOutsideCollaborator:
+		users, resp, err = client.Organizations.ListOutsideCollaborators(ctx, orgName, &github.ListOutsideCollaboratorsOptions{ListOptions: listOpts})
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("github-connector: ListOutsideCollaborators failed: %w", err)
+		}
+		// Outside collaborators aren't linked to the org's SAML identity provider.
+		hasSamlBool = false
+
+	default:
+		return nil, "", nil, fmt.Errorf("unexpected page state while listing users")
 	}
 
 	restApiRateLimit, err = extractRateLimitData(resp)
@@ -145,10 +376,61 @@ func (o *userResourceType) List(ctx context.Context, parentID *v2.ResourceId, pt
 		return nil, "", nil, err
 	}
 
-	q := listUsersQuery{}
+	twoFactorDisabled, err := o.twoFactorDisabled(ctx, client, orgName, role)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	enterpriseTwoFactorEnabled := o.enterpriseTwoFactor(ctx)
+
+	allOrgUsers, err := o.listOrgUsers(ctx)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	samlIdentities, graphqlRateLimit, err := o.samlIdentities(ctx)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	var identities map[string]*externalIdentity
+	if hasSamlBool {
+		identities = samlIdentities[orgName]
+	}
+
+	// Only org members have emails on the verified domains of the org.
+	var verifiedEmails map[string][]string
+	if role == orgRoleMember {
+		var rateLimit *v2.RateLimitDescription
+		verifiedEmails, rateLimit, err = o.verifiedEmails(ctx, graphqlClient, orgName)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		if rateLimit != nil && (graphqlRateLimit == nil || rateLimit.Remaining < graphqlRateLimit.Remaining) {
+			graphqlRateLimit = rateLimit
+		}
+	}
+
+	var report *activityReport
+	if o.activityConfig.enabled() {
+		report, err = o.activity(ctx)
+		if err != nil {
+			return nil, "", nil, err
+		}
+	}
+
 	rv := make([]*v2.Resource, 0, len(users))
 	for _, user := range users {
-		u, res, err := o.client.Users.GetByID(ctx, user.GetID())
+		opts := []userResourceOption{
+			withOrgUsers(allOrgUsers, user.GetID()),
+			withSAMLIdentities(samlIdentities, allOrgUsers, user),
+		}
+		// The two-factor authentication of enterprise members is read from the enterprise, and otherwise from the org.
+		if enabled, ok := enterpriseTwoFactorEnabled[user.GetID()]; ok {
+			opts = append(opts, withMFAEnabled(enabled))
+		} else if twoFactorDisabled != nil {
+			_, disabled := twoFactorDisabled[user.GetID()]
+			opts = append(opts, withMFAEnabled(!disabled))
+		}
+		u, res, err := client.Users.GetByID(ctx, user.GetID())
 		if err != nil {
 			// This undocumented API can return 404 for some users. If this fails it means we won't get some of their details like email
 			if res == nil || res.StatusCode != http.StatusNotFound {
@@ -159,52 +441,49 @@ func (o *userResourceType) List(ctx context.Context, parentID *v2.ResourceId, pt
 		}
 		userEmail := u.GetEmail()
 		var extraEmails []string
-		if hasSamlBool {
-			variables := map[string]interface{}{
-				"orgLoginName": githubv4.String(orgName),
-				"userName":     githubv4.String(u.GetLogin()),
+		// A verified domain email is preferred over the public email, which most users don't set.
+		if emails := verifiedEmails[strings.ToLower(u.GetLogin())]; len(emails) > 0 {
+			publicEmail := userEmail
+			userEmail, extraEmails = emails[0], append([]string{}, emails[1:]...)
+			if publicEmail != "" && !containsFold(emails, publicEmail) {
+				extraEmails = append(extraEmails, publicEmail)
 			}
-			err = o.graphqlClient.Query(ctx, &q, variables)
-			if err != nil {
-				return nil, "", nil, err
+		}
+		identity, ok := identities[strings.ToLower(u.GetLogin())]
+		if ok {
+			if email, emails := identity.userEmails(); email != "" {
+				userEmail, extraEmails = email, emails
 			}
-			if len(q.Organization.SamlIdentityProvider.ExternalIdentities.Edges) == 1 {
-				samlIdent := q.Organization.SamlIdentityProvider.ExternalIdentities.Edges[0].Node.SamlIdentity
-				userEmail = samlIdent.NameId
-				setUserEmail := false
-
-				if userEmail != "" {
-					setUserEmail = true
-				}
-				for _, email := range samlIdent.Emails {
-					ok := isEmail(email.Value)
-					if !ok {
-						continue
-					}
-
-					if !setUserEmail {
-						userEmail = email.Value
-						setUserEmail = true
-					} else {
-						extraEmails = append(extraEmails, email.Value)
-					}
-				}
+			opts = append(opts, withExternalIdentity(identity))
+		}
+
+		if report != nil {
+			activity := report.activity(u.GetLogin())
+			if o.activityConfig.lastActivity {
+				opts = append(opts, withActivity(activity))
+			}
+			if o.activityConfig.dormantDays > 0 && activity.isDormant(o.activityConfig.dormantDays) {
+				opts = append(opts, withDormant())
 			}
 		}
-		ur, err := userResource(ctx, u, userEmail, extraEmails)
+
+		ur, err := userResource(ctx, u, userEmail, extraEmails, opts...)
 		if err != nil {
 			return nil, "", nil, err
 		}
 
+		// The identity provider ID lets the user be correlated with their identity, whatever their emails are.
+		if ok && identity.guid != "" {
+			ur.ExternalId = &v2.ExternalId{
+				Id:          identity.guid,
+				Description: "The ID of the identity provider identity linked to the user",
+			}
+		}
+
 		rv = append(rv, ur)
 	}
 	annotations.WithRateLimiting(restApiRateLimit)
-	if *o.hasSAMLEnabled && int64(q.RateLimit.Remaining) < restApiRateLimit.Remaining {
-		graphqlRateLimit := &v2.RateLimitDescription{
-			Limit:     int64(q.RateLimit.Limit),
-			Remaining: int64(q.RateLimit.Remaining),
-			ResetAt:   timestamppb.New(q.RateLimit.ResetAt.Time),
-		}
+	if graphqlRateLimit != nil && graphqlRateLimit.Remaining < restApiRateLimit.Remaining {
 		annotations.WithRateLimiting(graphqlRateLimit)
 	}
 
@@ -216,6 +495,274 @@ func isEmail(email string) bool {
 	return err == nil
 }
 
+// listBillingManagers returns the billing managers of the org, which are listed at once.
+func (o *userResourceType) listBillingManagers(
+	ctx context.Context,
+	bag *pagination.Bag,
+	orgID *v2.ResourceId,
+) ([]*v2.Resource, string, annotations.Annotations, error) {
+	pageToken, err := bag.NextToken("")
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	allOrgUsers, err := o.listOrgUsers(ctx)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	users, err := o.orgCache.OrgUsers(ctx, orgID)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	rv := make([]*v2.Resource, 0, len(users.billingManagers))
+	for _, user := range users.billingManagers {
+		ur, err := userResource(ctx, user, user.GetEmail(), nil, withOrgUsers(allOrgUsers, user.GetID()))
+		if err != nil {
+			return nil, "", nil, err
+		}
+		rv = append(rv, ur)
+	}
+
+	return rv, pageToken, nil, nil
+}
+
+// listOrgUsers returns the outside collaborators and billing managers of every synced org, keyed by org login.
+func (o *userResourceType) listOrgUsers(ctx context.Context) (map[string]*orgUsers, error) {
+	orgIDs, err := o.orgCache.OrgIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rv := make(map[string]*orgUsers, len(orgIDs))
+	for _, id := range orgIDs {
+		orgName, err := o.orgCache.GetOrgName(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		users, err := o.orgCache.OrgUsers(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		rv[orgName] = users
+	}
+
+	return rv, nil
+}
+
+// externalIdentities returns the external identities of the org members, which are listed on first use. The rate limit
+// is only returned when the identities were listed.
+func (o *userResourceType) externalIdentities(
+	ctx context.Context,
+	graphqlClient *githubv4.Client,
+	orgName string,
+) (map[string]*externalIdentity, *v2.RateLimitDescription, error) {
+	o.externalIdentitiesMtx.Lock()
+	defer o.externalIdentitiesMtx.Unlock()
+
+	if identities, ok := o.externalIdentitiesCache[orgName]; ok {
+		return identities, nil, nil
+	}
+
+	identities, rateLimit, err := listExternalIdentities(ctx, graphqlClient, orgName)
+	if err != nil {
+		return nil, nil, err
+	}
+	o.externalIdentitiesCache[orgName] = identities
+
+	return identities, rateLimit, nil
+}
+
+// samlIdentities returns the external identities of the members of every synced org with SAML single sign-on, keyed by
+// org login.
+func (o *userResourceType) samlIdentities(
+	ctx context.Context,
+) (map[string]map[string]*externalIdentity, *v2.RateLimitDescription, error) {
+	orgIDs, err := o.orgCache.OrgIDs(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rv := make(map[string]map[string]*externalIdentity)
+	var rateLimit *v2.RateLimitDescription
+	for _, id := range orgIDs {
+		orgName, err := o.orgCache.GetOrgName(ctx, id)
+		if err != nil {
+			return nil, nil, err
+		}
+		hasSAML, err := o.hasSAML(ctx, id, orgName)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !hasSAML {
+			continue
+		}
+
+		graphqlClient, err := o.graphqlClientForOrg(id)
+		if err != nil {
+			return nil, nil, err
+		}
+		identities, orgRateLimit, err := o.externalIdentities(ctx, graphqlClient, orgName)
+		if err != nil {
+			return nil, nil, err
+		}
+		if orgRateLimit != nil && (rateLimit == nil || orgRateLimit.Remaining < rateLimit.Remaining) {
+			rateLimit = orgRateLimit
+		}
+		rv[orgName] = identities
+	}
+
+	return rv, rateLimit, nil
+}
+
+// verifiedEmails returns the verified domain emails of the org members, which are listed on first use. The emails are
+// only visible to org owners, so no emails are returned when they can't be listed.
+func (o *userResourceType) verifiedEmails(
+	ctx context.Context,
+	graphqlClient *githubv4.Client,
+	orgName string,
+) (map[string][]string, *v2.RateLimitDescription, error) {
+	o.verifiedEmailsMtx.Lock()
+	defer o.verifiedEmailsMtx.Unlock()
+
+	if emails, ok := o.verifiedEmailsCache[orgName]; ok {
+		return emails, nil, nil
+	}
+
+	emails, rateLimit, err := listVerifiedDomainEmails(ctx, graphqlClient, orgName)
+	if err != nil {
+		ctxzap.Extract(ctx).Warn("can't list verified domain emails", zap.String("org", orgName), zap.Error(err))
+		emails = make(map[string][]string)
+	}
+	o.verifiedEmailsCache[orgName] = emails
+
+	return emails, rateLimit, nil
+}
+
+// twoFactorDisabled returns the org members or outside collaborators without two-factor authentication, which are
+// listed on the first page of users with the role and cached until the users of the org are listed again.
+func (o *userResourceType) twoFactorDisabled(ctx context.Context, client *github.Client, orgName string, role string) (map[int64]struct{}, error) {
+	o.twoFactorDisabledMtx.Lock()
+	defer o.twoFactorDisabledMtx.Unlock()
+
+	key := twoFactorDisabledKey(orgName, role)
+	if users, ok := o.twoFactorDisabledCache[key]; ok {
+		return users, nil
+	}
+
+	users, err := listTwoFactorDisabled(ctx, client, orgName, role)
+	if err != nil {
+		return nil, err
+	}
+	o.twoFactorDisabledCache[key] = users
+
+	return users, nil
+}
+
+func twoFactorDisabledKey(orgName string, role string) string {
+	return orgName + "/" + role
+}
+
+// enterpriseTwoFactor is whether each member of the enterprise has two-factor authentication enabled, by user ID.
+type enterpriseTwoFactor struct {
+	readAt  time.Time
+	members map[int64]bool
+}
+
+// enterpriseTwoFactor returns whether each enterprise member has two-factor authentication enabled when --enterprise is
+// set. Like the activity of users, it's reused for activityMaxAge, so that it's read once per sync rather than once per
+// org. It returns nil when the credentials can't read it.
+func (o *userResourceType) enterpriseTwoFactor(ctx context.Context) map[int64]bool {
+	if o.accountConfig.enterprise == "" || o.graphqlClient == nil {
+		return nil
+	}
+
+	o.twoFactorDisabledMtx.Lock()
+	defer o.twoFactorDisabledMtx.Unlock()
+
+	if o.enterpriseTwoFactorCache != nil && time.Since(o.enterpriseTwoFactorCache.readAt) < activityMaxAge {
+		return o.enterpriseTwoFactorCache.members
+	}
+
+	members, err := listEnterpriseTwoFactor(ctx, o.graphqlClient, o.accountConfig.enterprise)
+	if err != nil {
+		// Only enterprise owners can read the two-factor authentication of members.
+		ctxzap.Extract(ctx).Warn("can't list the two-factor authentication of enterprise members", zap.Error(err))
+		members = nil
+	}
+	o.enterpriseTwoFactorCache = &enterpriseTwoFactor{readAt: time.Now(), members: members}
+
+	return members
+}
+
+// listTwoFactorDisabled returns the IDs of the org members or outside collaborators without two-factor authentication,
+// depending on the role. The REST API only reports the two-factor authentication of other users to org owners, so it
+// returns nil when the filter isn't allowed.
+func listTwoFactorDisabled(ctx context.Context, client *github.Client, orgName string, role string) (map[int64]struct{}, error) {
+	rv := make(map[int64]struct{})
+	listOpts := github.ListOptions{PerPage: 100}
+	for {
+		var users []*github.User
+		var resp *github.Response
+		var err error
+		switch role {
+		case orgRoleMember:
+			users, resp, err = client.Organizations.ListMembers(ctx, orgName, &github.ListMembersOptions{
+				Filter:      "2fa_disabled",
+				ListOptions: listOpts,
+			})
+		case orgRoleOutsideCollaborator:
+			users, resp, err = client.Organizations.ListOutsideCollaborators(ctx, orgName, &github.ListOutsideCollaboratorsOptions{
+				Filter:      "2fa_disabled",
+				ListOptions: listOpts,
+			})
+		default:
+			return nil, fmt.Errorf("unexpected role while listing users without two-factor authentication")
+		}
+		if err != nil {
+			if resp != nil && (resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusUnprocessableEntity) {
+				ctxzap.Extract(ctx).Warn("can't list users without two-factor authentication", zap.String("org", orgName), zap.Error(err))
+				return nil, nil
+			}
+			return nil, fmt.Errorf("github-connector: failed to list users without two-factor authentication: %w", err)
+		}
+
+		for _, user := range users {
+			rv[user.GetID()] = struct{}{}
+		}
+
+		if resp.NextPage == 0 {
+			return rv, nil
+		}
+		listOpts.Page = resp.NextPage
+	}
+}
+
+// activity returns the activity of every user. It's read in bulk and reused for activityMaxAge, so that it's read once
+// per sync rather than once per org.
+func (o *userResourceType) activity(ctx context.Context) (*activityReport, error) {
+	o.activityMtx.Lock()
+	defer o.activityMtx.Unlock()
+
+	if o.activityCache != nil && time.Since(o.activityCache.readAt) < activityMaxAge {
+		return o.activityCache, nil
+	}
+
+	// The activity of users can't be read with GitHub App credentials, so it's unknown.
+	if o.client == nil {
+		o.activityCache = &activityReport{readAt: time.Now()}
+		return o.activityCache, nil
+	}
+
+	report, err := readActivityReport(ctx, o.client, o.activityConfig)
+	if err != nil {
+		return nil, err
+	}
+	o.activityCache = report
+
+	return report, nil
+}
+
 func (o *userResourceType) Entitlements(_ context.Context, _ *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
 	return nil, "", nil, nil
 }
@@ -224,33 +771,268 @@ func (o *userResourceType) Grants(_ context.Context, _ *v2.Resource, _ *paginati
 	return nil, "", nil, nil
 }
 
-func userBuilder(client *github.Client, hasSAMLEnabled *bool, graphqlClient *githubv4.Client, orgCache *orgNameCache) *userResourceType {
+func (o *userResourceType) CreateAccount(
+	ctx context.Context,
+	accountInfo *v2.AccountInfo,
+	_ *v2.CredentialOptions,
+) (connectorbuilder.CreateAccountResponse, []*v2.PlaintextData, annotations.Annotations, error) {
+	if o.accountConfig.emu {
+		return o.createManagedUser(ctx, accountInfo)
+	}
+
+	return o.inviteAccount(ctx, accountInfo)
+}
+
+// inviteAccount invites the primary email of the account to an org. The account is pending until the invitation is
+// accepted, at which point the user is synced as an org member.
+func (o *userResourceType) inviteAccount(
+	ctx context.Context,
+	accountInfo *v2.AccountInfo,
+) (connectorbuilder.CreateAccountResponse, []*v2.PlaintextData, annotations.Annotations, error) {
+	var email string
+	for _, e := range accountInfo.GetEmails() {
+		if email == "" || e.GetIsPrimary() {
+			email = e.GetAddress()
+		}
+	}
+	if email == "" {
+		return nil, nil, nil, fmt.Errorf("github-connectorv2: an email is required to invite a user")
+	}
+
+	orgName, _ := resource.GetProfileStringValue(accountInfo.GetProfile(), "org")
+	if orgName == "" && len(o.accountConfig.orgs) == 1 {
+		orgName = o.accountConfig.orgs[0]
+	}
+	if orgName == "" {
+		return nil, nil, nil, fmt.Errorf("github-connectorv2: an org is required to invite a user, set org in the account profile or configure a single org")
+	}
+
+	orgID, err := o.orgCache.GetOrgID(ctx, orgName)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	client, err := o.orgCache.GetClient(orgID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	orgName, err = o.orgCache.GetOrgName(ctx, orgID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	invitation, err := findOrgInvitation(ctx, client, orgName, invitationForEmail(email))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if invitation == nil {
+		role := o.accountConfig.inviteRole
+		if role == "" {
+			role = orgRoleDirectMember
+		}
+
+		teamIDs := make([]int64, 0, len(o.accountConfig.inviteTeams))
+		for _, slug := range o.accountConfig.inviteTeams {
+			team, _, err := client.Teams.GetTeamBySlug(ctx, orgName, slug)
+			if err != nil {
+				return nil, nil, nil, fmt.Errorf("github-connectorv2: failed to get team %s: %w", slug, err)
+			}
+			teamIDs = append(teamIDs, team.GetID())
+		}
+
+		invitation, _, err = client.Organizations.CreateOrgInvitation(ctx, orgName, &github.CreateOrgInvitationOptions{
+			Email:  github.String(email),
+			Role:   github.String(role),
+			TeamID: teamIDs,
+		})
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("github-connectorv2: failed to invite %s to org: %w", email, err)
+		}
+	}
+
+	ir, err := invitationResource(invitation, orgID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return &v2.CreateAccountResponse_ActionRequiredResult{
+		Resource:              ir,
+		Message:               fmt.Sprintf("An invitation to the %s org was sent to %s and is pending until it's accepted.", orgName, email),
+		IsCreateAccountResult: true,
+	}, nil, nil, nil
+}
+
+// createManagedUser provisions a managed user in the enterprise through SCIM.
+func (o *userResourceType) createManagedUser(
+	ctx context.Context,
+	accountInfo *v2.AccountInfo,
+) (connectorbuilder.CreateAccountResponse, []*v2.PlaintextData, annotations.Annotations, error) {
+	login := accountInfo.GetLogin()
+	if login == "" {
+		return nil, nil, nil, fmt.Errorf("github-connectorv2: a login is required to provision a managed user")
+	}
+
+	user := &scimUser{
+		Schemas:    []string{scimUserSchema},
+		ExternalID: login,
+		UserName:   login,
+		Roles:      []scimRole{{Value: scimDefaultUserRole}},
+		Active:     true,
+	}
+
+	for _, email := range accountInfo.GetEmails() {
+		user.Emails = append(user.Emails, scimEmail{Value: email.GetAddress(), Type: "work", Primary: email.GetIsPrimary()})
+	}
+	if len(user.Emails) == 0 {
+		return nil, nil, nil, fmt.Errorf("github-connectorv2: an email is required to provision a managed user")
+	}
+
+	profile := accountInfo.GetProfile()
+	if externalID, ok := resource.GetProfileStringValue(profile, "external_id"); ok && externalID != "" {
+		user.ExternalID = externalID
+	}
+	user.Name.GivenName, _ = resource.GetProfileStringValue(profile, "first_name")
+	user.Name.FamilyName, _ = resource.GetProfileStringValue(profile, "last_name")
+	user.Name.Formatted = strings.TrimSpace(user.Name.GivenName + " " + user.Name.FamilyName)
+	user.DisplayName, _ = resource.GetProfileStringValue(profile, "display_name")
+	if user.DisplayName == "" {
+		user.DisplayName = user.Name.Formatted
+	}
+	if user.DisplayName == "" {
+		user.DisplayName = login
+	}
+
+	created, err := createSCIMUser(ctx, o.client, o.accountConfig.enterprise, user)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	// The GitHub login of a managed user is derived from the SCIM user name, so the user is looked up by their identity.
+	_, userID, err := findExternalIdentity(ctx, o.graphqlClient, o.accountConfig.enterprise, "", created.UserName)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if userID == 0 {
+		return &v2.CreateAccountResponse_SuccessResult{IsCreateAccountResult: true}, nil, nil, nil
+	}
+
+	ghUser, _, err := o.client.Users.GetByID(ctx, userID)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("github-connectorv2: failed to get provisioned user: %w", err)
+	}
+
+	ur, err := userResource(ctx, ghUser, user.Emails[0].Value, nil)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return &v2.CreateAccountResponse_SuccessResult{Resource: ur, IsCreateAccountResult: true}, nil, nil, nil
+}
+
+// Create isn't supported, users are created with CreateAccount.
+func (o *userResourceType) Create(_ context.Context, _ *v2.Resource) (*v2.Resource, annotations.Annotations, error) {
+	return nil, nil, status.Error(codes.Unimplemented, "github-connector: users are created with CreateAccount")
+}
+
+// Delete suspends a managed user of the enterprise. Suspending rather than deleting the user keeps their contributions
+// and lets them be reactivated through SCIM.
+func (o *userResourceType) Delete(ctx context.Context, resourceID *v2.ResourceId) (annotations.Annotations, error) {
+	if !o.accountConfig.emu {
+		return nil, status.Error(codes.Unimplemented, "github-connector: accounts can only be deleted in enterprises with managed users")
+	}
+
+	userID, err := parseResourceToGitHub(resourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	user, _, err := o.client.Users.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("github-connectorv2: failed to get user: %w", err)
+	}
+
+	scimUserID, _, err := findExternalIdentity(ctx, o.graphqlClient, o.accountConfig.enterprise, user.GetLogin(), "")
+	if err != nil {
+		return nil, err
+	}
+	if scimUserID == "" {
+		return nil, fmt.Errorf("github-connectorv2: user %s wasn't provisioned through SCIM", user.GetLogin())
+	}
+
+	err = suspendSCIMUser(ctx, o.client, o.accountConfig.enterprise, scimUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+func userBuilder(
+	client *github.Client,
+	hasSAMLEnabled *bool,
+	graphqlClient *githubv4.Client,
+	orgCache *orgNameCache,
+	accountConfig accountConfig,
+	activityConfig activityConfig,
+) *userResourceType {
 	return &userResourceType{
 		resourceType:   resourceTypeUser,
 		client:         client,
 		graphqlClient:  graphqlClient,
 		hasSAMLEnabled: hasSAMLEnabled,
 		orgCache:       orgCache,
+		accountConfig:  accountConfig,
+		activityConfig: activityConfig,
+
+		externalIdentitiesCache: make(map[string]map[string]*externalIdentity),
+		verifiedEmailsCache:     make(map[string]map[string][]string),
+		twoFactorDisabledCache:  make(map[string]map[int64]struct{}),
+		samlEnabledCache:        make(map[string]bool),
 	}
 }
 
-func (o *userResourceType) hasSAML(ctx context.Context, orgName string) (bool, error) {
+// graphqlClientForOrg returns the GraphQL client authenticated for the org, falling back to the default client. It
+// returns an error for orgs without a GitHub App installation when authenticating as a GitHub App.
+func (o *userResourceType) graphqlClientForOrg(orgID *v2.ResourceId) (*githubv4.Client, error) {
+	if c := o.orgCache.GetGraphqlClient(orgID); c != nil {
+		return c, nil
+	}
+
+	if o.graphqlClient == nil {
+		return nil, fmt.Errorf("github-connector: the GitHub App isn't installed on org %s", orgID.GetResource())
+	}
+
+	return o.graphqlClient, nil
+}
+
+// hasSAML returns true if the org has SAML single sign-on, which is looked up once for every org unless it was set for
+// all orgs.
+func (o *userResourceType) hasSAML(ctx context.Context, orgID *v2.ResourceId, orgName string) (bool, error) {
 	if o.hasSAMLEnabled != nil {
 		return *o.hasSAMLEnabled, nil
 	}
 
-	samlBool := false
+	o.samlEnabledMtx.Lock()
+	defer o.samlEnabledMtx.Unlock()
+
+	if samlEnabled, ok := o.samlEnabledCache[orgID.GetResource()]; ok {
+		return samlEnabled, nil
+	}
+
 	q := hasSAMLQuery{}
 	variables := map[string]interface{}{
 		"orgLoginName": githubv4.String(orgName),
 	}
-	err := o.graphqlClient.Query(ctx, &q, variables)
+	graphqlClient, err := o.graphqlClientForOrg(orgID)
 	if err != nil {
 		return false, err
 	}
-	if q.Organization.SamlIdentityProvider.Id != "" {
-		samlBool = true
+	err = graphqlClient.Query(ctx, &q, variables)
+	if err != nil {
+		return false, err
 	}
-	o.hasSAMLEnabled = &samlBool
-	return *o.hasSAMLEnabled, nil
+
+	samlEnabled := q.Organization.SamlIdentityProvider.Id != ""
+	o.samlEnabledCache[orgID.GetResource()] = samlEnabled
+	return samlEnabled, nil
 }
@@ -3,13 +3,19 @@ package connector
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/conductorone/baton-github/test"
 	"github.com/conductorone/baton-github/test/mocks"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
 	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
 	"github.com/google/go-github/v63/github"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
 )
 
 func TestUsersList(t *testing.T) {
@@ -48,18 +54,606 @@ func TestUsersList(t *testing.T) {
 				testCase.hasSamlEnabled,
 				graphQLClient,
 				cache,
+				accountConfig{},
+				activityConfig{},
 			)
 
-			users, nextToken, annotations, err := client.List(
-				ctx,
-				organization.Id,
-				&pagination.Token{},
-			)
-			require.Nil(t, err)
-			test.AssertNoRatelimitAnnotations(t, annotations)
-			require.Equal(t, "", nextToken)
+			users := test.ExhaustPagination(t, func(pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+				return client.List(ctx, organization.Id, pToken)
+			})
 			require.Len(t, users, 1)
 			require.Equal(t, *githubUser.Login, users[0].Id.Resource)
 		})
 	}
+
+	for _, testCase := range testCases {
+		t.Run(fmt.Sprintf("should read the emails of users (SAML:%s)", testCase.message), func(t *testing.T) {
+			seed := seedMockGitHub(t)
+
+			organization, err := organizationResource(ctx, seed.organization, nil)
+			require.Nil(t, err)
+
+			client := userBuilder(seed.client, testCase.hasSamlEnabled, mocks.MockGraphQL(), seed.cache, accountConfig{}, activityConfig{})
+
+			users := test.ExhaustPagination(t, func(pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+				return client.List(ctx, organization.Id, pToken)
+			})
+			require.Len(t, users, 1)
+
+			userTrait, err := resource.GetUserTrait(users[0])
+			require.Nil(t, err)
+			// SAML is detected from the organization when it isn't set.
+			if testCase.hasSamlEnabled == nil || *testCase.hasSamlEnabled {
+				require.Len(t, userTrait.Emails, 3)
+				require.Equal(t, "56@idp.example.com", userTrait.Emails[0].Address)
+				require.Equal(t, "octocat@idp.example.com", userTrait.Emails[1].Address)
+				require.Equal(t, "octocat@corp.example.com", userTrait.Emails[2].Address)
+
+				require.Equal(t, "d4a1c1e0-5c3a-4c8e-9f1b-0a2b3c4d5e6f", users[0].GetExternalId().GetId())
+				profile := userTrait.Profile.GetFields()
+				require.Equal(t, "56@idp.example.com", profile["saml_name_id"].GetStringValue())
+				require.Equal(t, "octocat", profile["scim_username"].GetStringValue())
+				require.Equal(t, "octocat@corp.example.com,octocat@idp.example.com", profile["scim_emails"].GetStringValue())
+			} else {
+				// The verified domain email is preferred over the public email.
+				require.Len(t, userTrait.Emails, 2)
+				require.Equal(t, "octocat@example.com", userTrait.Emails[0].Address)
+				require.True(t, userTrait.Emails[0].IsPrimary)
+				require.Equal(t, seed.user.GetEmail(), userTrait.Emails[1].Address)
+				require.Nil(t, users[0].GetExternalId())
+			}
+		})
+	}
+
+	t.Run("should fall back to the SCIM emails when the NameID isn't an email", func(t *testing.T) {
+		identity := &externalIdentity{
+			guid:         "d4a1c1e0-5c3a-4c8e-9f1b-0a2b3c4d5e6f",
+			nameID:       "octocat",
+			scimUserName: "octocat",
+			scimEmails:   []string{"octocat@corp.example.com", "OCTOCAT@corp.example.com", "octocat@idp.example.com"},
+		}
+		primary, extra := identity.userEmails()
+		require.Equal(t, "octocat@corp.example.com", primary)
+		require.Equal(t, []string{"octocat@idp.example.com"}, extra)
+	})
+
+	t.Run("should list outside collaborators as users", func(t *testing.T) {
+		seed := seedMockGitHub(t)
+		githubCollaborator := seed.mgh.AddOutsideCollaborator(seed.organization.GetID(), 90)
+
+		organization, err := organizationResource(ctx, seed.organization, nil)
+		require.Nil(t, err)
+
+		client := userBuilder(seed.client, &falseBool, mocks.MockGraphQL(), seed.cache, accountConfig{}, activityConfig{})
+
+		users := test.ExhaustPagination(t, func(pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+			return client.List(ctx, organization.Id, pToken)
+		})
+		require.Len(t, users, 2)
+
+		var collaborator *v2.Resource
+		for _, user := range users {
+			if user.Id.Resource == githubCollaborator.GetLogin() {
+				collaborator = user
+			}
+		}
+		require.NotNil(t, collaborator)
+
+		userTrait, err := resource.GetUserTrait(collaborator)
+		require.Nil(t, err)
+		require.True(t, userTrait.Profile.GetFields()["outside_collaborator"].GetBoolValue())
+		require.Equal(t, seed.organization.GetLogin(), userTrait.Profile.GetFields()["outside_collaborator_orgs"].GetStringValue())
+		require.False(t, userTrait.Profile.GetFields()["billing_manager"].GetBoolValue())
+	})
+
+	t.Run("should flag outside collaborators of other orgs", func(t *testing.T) {
+		seed := seedMockGitHub(t)
+		otherOrganization := seed.mgh.AddOrganization(13, "organization-13")
+		seed.mgh.AddOutsideCollaborator(otherOrganization.GetID(), seed.user.GetID())
+
+		organization, err := organizationResource(ctx, seed.organization, nil)
+		require.Nil(t, err)
+
+		client := userBuilder(seed.client, &falseBool, mocks.MockGraphQL(), seed.cache, accountConfig{}, activityConfig{})
+
+		users := test.ExhaustPagination(t, func(pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+			return client.List(ctx, organization.Id, pToken)
+		})
+		require.Len(t, users, 1)
+
+		userTrait, err := resource.GetUserTrait(users[0])
+		require.Nil(t, err)
+		require.True(t, userTrait.Profile.GetFields()["outside_collaborator"].GetBoolValue())
+		require.Equal(t, otherOrganization.GetLogin(), userTrait.Profile.GetFields()["outside_collaborator_orgs"].GetStringValue())
+		require.False(t, userTrait.Profile.GetFields()["billing_manager"].GetBoolValue())
+		require.Empty(t, userTrait.Profile.GetFields()["billing_manager_orgs"].GetStringValue())
+	})
+
+	t.Run("should only flag outside collaborators of synced orgs", func(t *testing.T) {
+		seed := seedMockGitHub(t)
+
+		// The authenticated user isn't an admin of the other org, so it isn't synced.
+		otherOrganization := seed.mgh.AddOrganization(13, "organization-13")
+		seed.mgh.SetViewerOrgRole(otherOrganization.GetID(), "member")
+		seed.mgh.AddOutsideCollaborator(otherOrganization.GetID(), seed.user.GetID())
+
+		organization, err := organizationResource(ctx, seed.organization, nil)
+		require.Nil(t, err)
+
+		client := userBuilder(seed.client, &falseBool, mocks.MockGraphQL(), seed.cache, accountConfig{}, activityConfig{})
+
+		users := test.ExhaustPagination(t, func(pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+			return client.List(ctx, organization.Id, pToken)
+		})
+		require.Len(t, users, 1)
+
+		userTrait, err := resource.GetUserTrait(users[0])
+		require.Nil(t, err)
+		require.False(t, userTrait.Profile.GetFields()["outside_collaborator"].GetBoolValue())
+		require.Empty(t, userTrait.Profile.GetFields()["outside_collaborator_orgs"].GetStringValue())
+
+		orgIDs, err := seed.cache.OrgIDs(ctx)
+		require.Nil(t, err)
+		require.Equal(t, []*v2.ResourceId{organization.Id}, orgIDs)
+	})
+
+	t.Run("should list billing managers as users", func(t *testing.T) {
+		seed := seedMockGitHub(t)
+		githubBillingManager := seed.mgh.AddBillingManager(seed.organization.GetID(), 91)
+
+		organization, err := organizationResource(ctx, seed.organization, nil)
+		require.Nil(t, err)
+
+		client := userBuilder(seed.client, &falseBool, mocks.MockGraphQL(), seed.cache, accountConfig{}, activityConfig{})
+
+		users := test.ExhaustPagination(t, func(pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+			return client.List(ctx, organization.Id, pToken)
+		})
+		require.Len(t, users, 2)
+		require.Equal(t, githubBillingManager.GetLogin(), users[1].Id.Resource)
+
+		userTrait, err := resource.GetUserTrait(users[1])
+		require.Nil(t, err)
+		require.True(t, userTrait.Profile.GetFields()["billing_manager"].GetBoolValue())
+		require.Equal(t, seed.organization.GetLogin(), userTrait.Profile.GetFields()["billing_manager_orgs"].GetStringValue())
+		require.False(t, userTrait.Profile.GetFields()["outside_collaborator"].GetBoolValue())
+	})
+
+	t.Run("should only list billing managers of synced orgs", func(t *testing.T) {
+		seed := seedMockGitHub(t)
+		otherOrganization := seed.mgh.AddOrganization(13, "organization-13")
+		seed.mgh.SetViewerOrgRole(otherOrganization.GetID(), "member")
+		seed.mgh.AddBillingManager(otherOrganization.GetID(), seed.user.GetID())
+
+		organization, err := organizationResource(ctx, seed.organization, nil)
+		require.Nil(t, err)
+		other, err := organizationResource(ctx, otherOrganization, nil)
+		require.Nil(t, err)
+
+		client := userBuilder(seed.client, &falseBool, mocks.MockGraphQL(), seed.cache, accountConfig{}, activityConfig{})
+
+		users := test.ExhaustPagination(t, func(pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+			return client.List(ctx, organization.Id, pToken)
+		})
+		require.Len(t, users, 1)
+
+		userTrait, err := resource.GetUserTrait(users[0])
+		require.Nil(t, err)
+		require.False(t, userTrait.Profile.GetFields()["billing_manager"].GetBoolValue())
+
+		otherUsers, err := seed.cache.OrgUsers(ctx, other.Id)
+		require.Nil(t, err)
+		require.Empty(t, otherUsers.billingManagers)
+	})
+}
+
+func TestUserAccounts(t *testing.T) {
+	ctx := context.Background()
+
+	falseBool := false
+
+	t.Run("should provision and suspend managed users", func(t *testing.T) {
+		seed := seedMockGitHub(t)
+
+		client := userBuilder(seed.client, &falseBool, mocks.MockGraphQL(), seed.cache, accountConfig{
+			enterprise: "acme",
+			emu:        true,
+		}, activityConfig{})
+
+		profile, err := structpb.NewStruct(map[string]interface{}{
+			"first_name": "Mona",
+			"last_name":  "Octocat",
+		})
+		require.Nil(t, err)
+
+		result, _, _, err := client.CreateAccount(ctx, &v2.AccountInfo{
+			Login:   "octocat",
+			Emails:  []*v2.AccountInfo_Email{{Address: "octocat@example.com", IsPrimary: true}},
+			Profile: profile,
+		}, nil)
+		require.Nil(t, err)
+
+		success, ok := result.(*v2.CreateAccountResponse_SuccessResult)
+		require.True(t, ok)
+		require.Equal(t, strconv.FormatInt(seed.user.GetID(), 10), success.Resource.Id.Resource)
+
+		active, ok := seed.mgh.IsSCIMUserActive("scim-octocat")
+		require.True(t, ok)
+		require.True(t, active)
+
+		_, err = client.Delete(ctx, success.Resource.Id)
+		require.Nil(t, err)
+
+		active, ok = seed.mgh.IsSCIMUserActive("scim-octocat")
+		require.True(t, ok)
+		require.False(t, active)
+	})
+
+	t.Run("should create accounts by email invitation", func(t *testing.T) {
+		seed := seedMockGitHub(t)
+
+		client := userBuilder(seed.client, &falseBool, mocks.MockGraphQL(), seed.cache, accountConfig{
+			orgs:        []string{seed.organization.GetLogin()},
+			inviteTeams: []string{fmt.Sprintf("team-%d", seed.team.GetID())},
+		}, activityConfig{})
+
+		accountInfo := &v2.AccountInfo{
+			Emails: []*v2.AccountInfo_Email{{Address: "octocat@example.com", IsPrimary: true}},
+		}
+
+		result, _, _, err := client.CreateAccount(ctx, accountInfo, nil)
+		require.Nil(t, err)
+
+		pending, ok := result.(*v2.CreateAccountResponse_ActionRequiredResult)
+		require.True(t, ok)
+		require.Equal(t, resourceTypeInvitation.Id, pending.Resource.Id.ResourceType)
+
+		userTrait, err := resource.GetUserTrait(pending.Resource)
+		require.Nil(t, err)
+		role, _ := resource.GetProfileStringValue(userTrait.Profile, "role")
+		require.Equal(t, orgRoleDirectMember, role)
+
+		// Creating the account again returns the pending invitation.
+		result, _, _, err = client.CreateAccount(ctx, accountInfo, nil)
+		require.Nil(t, err)
+		require.Equal(t, pending.Resource.Id, result.(*v2.CreateAccountResponse_ActionRequiredResult).Resource.Id)
+	})
+
+	t.Run("should invite accounts with the installation client of the org", func(t *testing.T) {
+		mgh := mocks.NewMockGitHub()
+
+		githubOrganization, _, _, _, _ := mgh.Seed()
+
+		// Authenticating as a GitHub App, there is no client for orgs without an installation.
+		installationClient := github.NewClient(mgh.Server())
+		cache := newOrgNameCache(nil)
+		cache.SetOrgClient(githubOrganization.GetID(), githubOrganization.GetLogin(), installationClient, nil)
+		client := userBuilder(nil, &falseBool, nil, cache, accountConfig{}, activityConfig{})
+
+		profile, err := structpb.NewStruct(map[string]interface{}{"org": githubOrganization.GetLogin()})
+		require.Nil(t, err)
+		result, _, _, err := client.CreateAccount(ctx, &v2.AccountInfo{
+			Emails:  []*v2.AccountInfo_Email{{Address: "octocat@example.com", IsPrimary: true}},
+			Profile: profile,
+		}, nil)
+		require.Nil(t, err)
+		require.IsType(t, &v2.CreateAccountResponse_ActionRequiredResult{}, result)
+
+		profile, err = structpb.NewStruct(map[string]interface{}{"org": "organization-34"})
+		require.Nil(t, err)
+		_, _, _, err = client.CreateAccount(ctx, &v2.AccountInfo{
+			Emails:  []*v2.AccountInfo_Email{{Address: "octocat@example.com", IsPrimary: true}},
+			Profile: profile,
+		}, nil)
+		require.ErrorContains(t, err, "organization-34")
+	})
+
+	t.Run("should require an org to invite accounts to", func(t *testing.T) {
+		mgh := mocks.NewMockGitHub()
+
+		githubClient := github.NewClient(mgh.Server())
+		cache := newOrgNameCache(githubClient)
+		client := userBuilder(githubClient, &falseBool, mocks.MockGraphQL(), cache, accountConfig{}, activityConfig{})
+
+		_, _, _, err := client.CreateAccount(ctx, &v2.AccountInfo{
+			Emails: []*v2.AccountInfo_Email{{Address: "octocat@example.com", IsPrimary: true}},
+		}, nil)
+		require.NotNil(t, err)
+	})
+}
+
+func TestUserStatus(t *testing.T) {
+	ctx := context.Background()
+
+	falseBool := false
+
+	t.Run("should disable suspended users", func(t *testing.T) {
+		user, err := userResource(ctx, &github.User{
+			ID:          github.Int64(56),
+			Login:       github.String("octocat"),
+			SuspendedAt: &github.Timestamp{Time: time.Now()},
+		}, "octocat@example.com", nil, withDormant())
+		require.Nil(t, err)
+
+		userTrait, err := resource.GetUserTrait(user)
+		require.Nil(t, err)
+		require.Equal(t, v2.UserTrait_Status_STATUS_DISABLED, userTrait.Status.Status)
+		require.Equal(t, userStatusSuspended, userTrait.Status.Details)
+	})
+
+	t.Run("should look up SAML single sign-on for every org", func(t *testing.T) {
+		seed := seedMockGitHub(t)
+		otherOrganization := seed.mgh.AddOrganization(13, "organization-without-saml-13", seed.user.GetID())
+
+		client := userBuilder(seed.client, nil, mocks.MockGraphQL(), seed.cache, accountConfig{}, activityConfig{})
+
+		for _, org := range []*github.Organization{seed.organization, otherOrganization} {
+			organization, err := organizationResource(ctx, org, nil)
+			require.Nil(t, err)
+
+			users := test.ExhaustPagination(t, func(pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+				return client.List(ctx, organization.Id, pToken)
+			})
+			require.Len(t, users, 1)
+
+			userTrait, err := resource.GetUserTrait(users[0])
+			require.Nil(t, err)
+			_, linked := userTrait.Profile.GetFields()["saml_name_id"]
+			require.Equal(t, org == seed.organization, linked)
+			require.Empty(t, userTrait.Status.Details)
+		}
+	})
+
+	t.Run("should flag users who haven't linked a SAML identity in any org", func(t *testing.T) {
+		seed := seedMockGitHub(t)
+		otherOrganization := seed.mgh.AddOrganization(13, "organization-without-saml-13", seed.user.GetID())
+		// The user is a member of both orgs, and hasn't linked a SAML identity in the org with SAML single sign-on.
+		unlinkedUser := seed.mgh.AddMember(seed.organization.GetID(), 57)
+		seed.mgh.AddMember(otherOrganization.GetID(), unlinkedUser.GetID())
+
+		client := userBuilder(seed.client, nil, mocks.MockGraphQL(), seed.cache, accountConfig{}, activityConfig{})
+
+		for _, org := range []*github.Organization{seed.organization, otherOrganization} {
+			organization, err := organizationResource(ctx, org, nil)
+			require.Nil(t, err)
+
+			users := test.ExhaustPagination(t, func(pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+				return client.List(ctx, organization.Id, pToken)
+			})
+			require.Len(t, users, 2)
+
+			for _, user := range users {
+				userTrait, err := resource.GetUserTrait(user)
+				require.Nil(t, err)
+
+				if user.Id.Resource == unlinkedUser.GetLogin() {
+					require.Equal(t, userStatusSAMLUnlinked, userTrait.Status.Details)
+					require.False(t, userTrait.Profile.GetFields()["saml_linked"].GetBoolValue())
+					require.Equal(t, seed.organization.GetLogin(), userTrait.Profile.GetFields()["saml_unlinked_orgs"].GetStringValue())
+				} else {
+					require.Empty(t, userTrait.Status.Details)
+					require.True(t, userTrait.Profile.GetFields()["saml_linked"].GetBoolValue())
+				}
+			}
+		}
+	})
+
+	t.Run("should flag users without recent activity as dormant", func(t *testing.T) {
+		seed := seedMockGitHub(t)
+		seed.mgh.SetUserLastActive(seed.user.GetLogin(), time.Now().Add(-100*24*time.Hour))
+
+		organization, err := organizationResource(ctx, seed.organization, nil)
+		require.Nil(t, err)
+
+		client := userBuilder(seed.client, &falseBool, mocks.MockGraphQL(), seed.cache, accountConfig{}, activityConfig{
+			dormantDays:      90,
+			enterpriseServer: true,
+		})
+
+		users := test.ExhaustPagination(t, func(pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+			return client.List(ctx, organization.Id, pToken)
+		})
+		require.Len(t, users, 1)
+
+		userTrait, err := resource.GetUserTrait(users[0])
+		require.Nil(t, err)
+		require.Equal(t, v2.UserTrait_Status_STATUS_ENABLED, userTrait.Status.Status)
+		require.Equal(t, userStatusDormant, userTrait.Status.Details)
+
+		seed.mgh.SetUserLastActive(seed.user.GetLogin(), time.Now())
+
+		// The report is read once per sync, so a new sync is needed to see the new activity.
+		client = userBuilder(seed.client, &falseBool, mocks.MockGraphQL(), seed.cache, accountConfig{}, activityConfig{
+			dormantDays:      90,
+			enterpriseServer: true,
+		})
+		users = test.ExhaustPagination(t, func(pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+			return client.List(ctx, organization.Id, pToken)
+		})
+		require.Len(t, users, 1)
+
+		userTrait, err = resource.GetUserTrait(users[0])
+		require.Nil(t, err)
+		require.Equal(t, v2.UserTrait_Status_STATUS_ENABLED, userTrait.Status.Status)
+		require.Empty(t, userTrait.Status.Details)
+	})
+
+	t.Run("should flag users who didn't sign in during the dormant period as dormant", func(t *testing.T) {
+		seed := seedMockGitHub(t)
+		seed.mgh.AddUserLogin("acme", seed.user.GetLogin(), time.Now().Add(-100*24*time.Hour))
+
+		organization, err := organizationResource(ctx, seed.organization, nil)
+		require.Nil(t, err)
+
+		client := userBuilder(seed.client, &falseBool, mocks.MockGraphQL(), seed.cache, accountConfig{}, activityConfig{
+			dormantDays: 90,
+			enterprise:  "acme",
+		})
+
+		users := test.ExhaustPagination(t, func(pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+			return client.List(ctx, organization.Id, pToken)
+		})
+		require.Len(t, users, 1)
+
+		userTrait, err := resource.GetUserTrait(users[0])
+		require.Nil(t, err)
+		require.Equal(t, userStatusDormant, userTrait.Status.Details)
+	})
+
+	t.Run("should not flag users whose activity is unknown as dormant", func(t *testing.T) {
+		seed := seedMockGitHub(t)
+
+		organization, err := organizationResource(ctx, seed.organization, nil)
+		require.Nil(t, err)
+
+		// The user isn't in the all users report, and there is no enterprise audit log to read sign ins from.
+		client := userBuilder(seed.client, &falseBool, mocks.MockGraphQL(), seed.cache, accountConfig{}, activityConfig{
+			dormantDays:      90,
+			lastActivity:     true,
+			enterpriseServer: true,
+		})
+
+		users := test.ExhaustPagination(t, func(pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+			return client.List(ctx, organization.Id, pToken)
+		})
+		require.Len(t, users, 1)
+
+		userTrait, err := resource.GetUserTrait(users[0])
+		require.Nil(t, err)
+		require.Empty(t, userTrait.Status.Details)
+		require.Nil(t, userTrait.LastLogin)
+		_, ok := resource.GetProfileStringValue(userTrait.Profile, "last_activity")
+		require.False(t, ok)
+	})
+
+	t.Run("should sync the activity of users", func(t *testing.T) {
+		seed := seedMockGitHub(t)
+
+		lastActivity := time.Now().Add(-10 * 24 * time.Hour).Truncate(time.Second)
+		lastLogin := time.Now().Add(-24 * time.Hour).Truncate(time.Second)
+		seed.mgh.SetUserLastActive(seed.user.GetLogin(), lastActivity)
+		seed.mgh.AddUserLogin("acme", seed.user.GetLogin(), lastLogin.Add(-24*time.Hour))
+		seed.mgh.AddUserLogin("acme", seed.user.GetLogin(), lastLogin)
+
+		organization, err := organizationResource(ctx, seed.organization, nil)
+		require.Nil(t, err)
+
+		client := userBuilder(seed.client, &falseBool, mocks.MockGraphQL(), seed.cache, accountConfig{}, activityConfig{
+			dormantDays:      5,
+			lastActivity:     true,
+			enterprise:       "acme",
+			enterpriseServer: true,
+		})
+
+		users := test.ExhaustPagination(t, func(pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+			return client.List(ctx, organization.Id, pToken)
+		})
+		require.Len(t, users, 1)
+
+		userTrait, err := resource.GetUserTrait(users[0])
+		require.Nil(t, err)
+		require.True(t, lastLogin.Equal(userTrait.LastLogin.AsTime()))
+		profileLastActivity, ok := resource.GetProfileStringValue(userTrait.Profile, "last_activity")
+		require.True(t, ok)
+		require.Equal(t, lastActivity.UTC().Format(time.RFC3339), profileLastActivity)
+
+		// The user signed in within the dormant period, even though their last activity in the report is older.
+		require.Empty(t, userTrait.Status.Details)
+	})
+
+	t.Run("should set when users were created", func(t *testing.T) {
+		createdAt := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+		user, err := userResource(ctx, &github.User{
+			ID:        github.Int64(56),
+			Login:     github.String("octocat"),
+			CreatedAt: &github.Timestamp{Time: createdAt},
+		}, "octocat@example.com", nil)
+		require.Nil(t, err)
+
+		userTrait, err := resource.GetUserTrait(user)
+		require.Nil(t, err)
+		require.Equal(t, createdAt, userTrait.CreatedAt.AsTime())
+	})
+
+	t.Run("should set the two-factor authentication of every user", func(t *testing.T) {
+		seed := seedMockGitHub(t)
+		githubCollaborator := seed.mgh.AddOutsideCollaborator(seed.organization.GetID(), 90)
+		seed.mgh.DisableTwoFactor(githubCollaborator.GetID())
+
+		organization, err := organizationResource(ctx, seed.organization, nil)
+		require.Nil(t, err)
+
+		client := userBuilder(seed.client, &falseBool, mocks.MockGraphQL(), seed.cache, accountConfig{}, activityConfig{})
+
+		users := test.ExhaustPagination(t, func(pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+			return client.List(ctx, organization.Id, pToken)
+		})
+		require.Len(t, users, 2)
+
+		mfaEnabled := make(map[string]bool)
+		for _, user := range users {
+			userTrait, err := resource.GetUserTrait(user)
+			require.Nil(t, err)
+			require.NotNil(t, userTrait.MfaStatus)
+			mfaEnabled[user.Id.Resource] = userTrait.MfaStatus.MfaEnabled
+		}
+		require.True(t, mfaEnabled[seed.user.GetLogin()])
+		require.False(t, mfaEnabled[githubCollaborator.GetLogin()])
+	})
+
+	t.Run("should set the two-factor authentication of enterprise members from the enterprise", func(t *testing.T) {
+		seed := seedMockGitHub(t)
+		githubCollaborator := seed.mgh.AddOutsideCollaborator(seed.organization.GetID(), 90)
+		seed.mgh.DisableTwoFactor(githubCollaborator.GetID())
+
+		organization, err := organizationResource(ctx, seed.organization, nil)
+		require.Nil(t, err)
+
+		client := userBuilder(seed.client, &falseBool, mocks.MockGraphQL(), seed.cache, accountConfig{enterprise: "acme"}, activityConfig{})
+
+		users := test.ExhaustPagination(t, func(pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+			return client.List(ctx, organization.Id, pToken)
+		})
+		require.Len(t, users, 2)
+
+		mfaEnabled := make(map[string]bool)
+		for _, user := range users {
+			userTrait, err := resource.GetUserTrait(user)
+			require.Nil(t, err)
+			require.NotNil(t, userTrait.MfaStatus)
+			mfaEnabled[user.Id.Resource] = userTrait.MfaStatus.MfaEnabled
+		}
+		// The enterprise member has two-factor authentication disabled in the enterprise, while the outside collaborator
+		// isn't an enterprise member and is read from the org.
+		require.False(t, mfaEnabled[seed.user.GetLogin()])
+		require.False(t, mfaEnabled[githubCollaborator.GetLogin()])
+	})
+
+	t.Run("should list users without two-factor authentication once per sync", func(t *testing.T) {
+		seed := seedMockGitHub(t)
+
+		client := userBuilder(seed.client, &falseBool, mocks.MockGraphQL(), seed.cache, accountConfig{}, activityConfig{})
+
+		twoFactorDisabled, err := client.twoFactorDisabled(ctx, seed.client, seed.organization.GetLogin(), orgRoleMember)
+		require.Nil(t, err)
+		require.Empty(t, twoFactorDisabled)
+
+		// Later pages of the sync reuse the users listed on the first page.
+		seed.mgh.DisableTwoFactor(seed.user.GetID())
+		twoFactorDisabled, err = client.twoFactorDisabled(ctx, seed.client, seed.organization.GetLogin(), orgRoleMember)
+		require.Nil(t, err)
+		require.Empty(t, twoFactorDisabled)
+
+		// The next sync lists them again.
+		organization, err := organizationResource(ctx, seed.organization, nil)
+		require.Nil(t, err)
+		users := test.ExhaustPagination(t, func(pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+			return client.List(ctx, organization.Id, pToken)
+		})
+		require.Len(t, users, 1)
+
+		userTrait, err := resource.GetUserTrait(users[0])
+		require.Nil(t, err)
+		require.False(t, userTrait.MfaStatus.MfaEnabled)
+	})
 }
@@ -0,0 +1,69 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/shurcooL/githubv4"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const verifiedDomainEmailsPageSize = 100
+
+// listVerifiedDomainEmailsQuery lists the org members with their emails on the verified domains of the org. The emails
+// are only visible to org owners.
+type listVerifiedDomainEmailsQuery struct {
+	Organization struct {
+		MembersWithRole struct {
+			Nodes []struct {
+				Login                            string
+				OrganizationVerifiedDomainEmails []string `graphql:"organizationVerifiedDomainEmails(login: $orgLoginName)"`
+			}
+			PageInfo graphqlPageInfo
+		} `graphql:"membersWithRole(first: $pageSize, after: $cursor)"`
+	} `graphql:"organization(login: $orgLoginName)"`
+	RateLimit graphqlRateLimit
+}
+
+// listVerifiedDomainEmails returns the verified domain emails of the org members by their lowercase login. Members
+// without an email on a verified domain are left out.
+func listVerifiedDomainEmails(
+	ctx context.Context,
+	graphqlClient *githubv4.Client,
+	orgName string,
+) (map[string][]string, *v2.RateLimitDescription, error) {
+	rv := make(map[string][]string)
+	var rateLimit *v2.RateLimitDescription
+	var cursor *githubv4.String
+	for {
+		q := listVerifiedDomainEmailsQuery{}
+		err := graphqlClient.Query(ctx, &q, map[string]interface{}{
+			"orgLoginName": githubv4.String(orgName),
+			"pageSize":     githubv4.Int(verifiedDomainEmailsPageSize),
+			"cursor":       cursor,
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("github-connector: failed to list verified domain emails of %s: %w", orgName, err)
+		}
+
+		members := q.Organization.MembersWithRole
+		for _, member := range members.Nodes {
+			if len(member.OrganizationVerifiedDomainEmails) > 0 {
+				rv[strings.ToLower(member.Login)] = member.OrganizationVerifiedDomainEmails
+			}
+		}
+
+		rateLimit = &v2.RateLimitDescription{
+			Limit:     int64(q.RateLimit.Limit),
+			Remaining: int64(q.RateLimit.Remaining),
+			ResetAt:   timestamppb.New(q.RateLimit.ResetAt.Time),
+		}
+
+		if !members.PageInfo.HasNextPage {
+			return rv, rateLimit, nil
+		}
+		cursor = githubv4.NewString(members.PageInfo.EndCursor)
+	}
+}
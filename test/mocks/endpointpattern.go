@@ -3,7 +3,7 @@ package mocks
 import "github.com/migueleliasweb/go-github-mock/src/mock"
 
 var GetUserById = mock.EndpointPattern{
-	Pattern: "/user/{id}",
+	Pattern: "/user/{id:[0-9]+}",
 	Method:  "GET",
 }
 
@@ -41,3 +41,68 @@ var GetOrganizationsTeamsMembershipsByTeamIdByUsername = mock.EndpointPattern{
 	Pattern: "/organizations/{org_id}/team/{team_id}/memberships/{username}",
 	Method:  "GET",
 }
+
+var GetOrgsCustomRepositoryRolesByOrg = mock.EndpointPattern{
+	Pattern: "/orgs/{org}/custom-repository-roles",
+	Method:  "GET",
+}
+
+var GetOrgsAuditLogByOrg = mock.EndpointPattern{
+	Pattern: "/orgs/{org}/audit-log",
+	Method:  "GET",
+}
+
+var GetEnterprisesAuditLogByEnterprise = mock.EndpointPattern{
+	Pattern: "/enterprises/{enterprise}/audit-log",
+	Method:  "GET",
+}
+
+var GetOrgsBillingManagersByOrg = mock.EndpointPattern{
+	Pattern: "/orgs/{org}/billing_managers",
+	Method:  "GET",
+}
+
+var GetStafftoolsReportsAllUsers = mock.EndpointPattern{
+	Pattern: "/stafftools/reports/all_users.csv",
+	Method:  "GET",
+}
+
+var DeleteOrganizationsTeamByTeamId = mock.EndpointPattern{
+	Pattern: "/organizations/{org_id}/team/{team_id}",
+	Method:  "DELETE",
+}
+
+var PatchOrganizationsTeamByTeamId = mock.EndpointPattern{
+	Pattern: "/organizations/{org_id}/team/{team_id}",
+	Method:  "PATCH",
+}
+
+var GetOrganizationsTeamsTeamsByTeamId = mock.EndpointPattern{
+	Pattern: "/organizations/{org_id}/team/{team_id}/teams",
+	Method:  "GET",
+}
+
+var PostScimV2EnterprisesUsersByEnterprise = mock.EndpointPattern{
+	Pattern: "/scim/v2/enterprises/{enterprise}/Users",
+	Method:  "POST",
+}
+
+var PatchScimV2EnterprisesUsersByEnterpriseByScimUserId = mock.EndpointPattern{
+	Pattern: "/scim/v2/enterprises/{enterprise}/Users/{scim_user_id}",
+	Method:  "PATCH",
+}
+
+var GetOrgsTeamSyncGroupsByOrg = mock.EndpointPattern{
+	Pattern: "/orgs/{org}/team-sync/groups",
+	Method:  "GET",
+}
+
+var GetOrgsTeamsTeamSyncGroupMappingsByOrgByTeamSlug = mock.EndpointPattern{
+	Pattern: "/orgs/{org}/teams/{team_slug}/team-sync/group-mappings",
+	Method:  "GET",
+}
+
+var PatchOrgsTeamsTeamSyncGroupMappingsByOrgByTeamSlug = mock.EndpointPattern{
+	Pattern: "/orgs/{org}/teams/{team_slug}/team-sync/group-mappings",
+	Method:  "PATCH",
+}
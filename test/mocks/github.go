@@ -1,13 +1,16 @@
 package mocks
 
 import (
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	mapset "github.com/deckarep/golang-set/v2"
 	"github.com/google/go-github/v63/github"
@@ -18,6 +21,24 @@ type MockGitHub struct {
 	teamMemberships         map[int64]mapset.Set[int64]
 	repositoryMemberships   map[int64]mapset.Set[int64]
 	organizationMemberships map[int64]mapset.Set[int64]
+	outsideCollaborators    map[int64]mapset.Set[int64]
+	repositoryTeams         map[int64]map[int64]string
+	repositoryRoles         map[int64]map[int64]string
+	customRepoRoles         map[int64][]github.CustomRepoRoles
+	orgRoles                map[int64][]github.CustomOrgRoles
+	orgRoleUsers            map[int64]mapset.Set[int64]
+	invitations             map[int64][]github.Invitation
+	auditLog                map[int64][]github.AuditEntry
+	enterpriseAuditLog      map[string][]github.AuditEntry
+	lastActive              map[string]time.Time
+	scimUsers               map[string]bool
+	twoFactorDisabled       mapset.Set[int64]
+	orgMemberRoles          map[int64]string
+	viewerOrgRoles          map[int64]string
+	billingManagers         map[int64]mapset.Set[int64]
+	securityManagerTeams    mapset.Set[int64]
+	idpGroups               map[string]github.IDPGroup
+	teamIDPGroups           map[int64][]string
 	organizations           map[int64]github.Organization
 	repositories            map[int64]github.Repository
 	teams                   map[int64]github.Team
@@ -29,6 +50,24 @@ func NewMockGitHub() *MockGitHub {
 		teamMemberships:         map[int64]mapset.Set[int64]{},
 		repositoryMemberships:   map[int64]mapset.Set[int64]{},
 		organizationMemberships: map[int64]mapset.Set[int64]{},
+		outsideCollaborators:    map[int64]mapset.Set[int64]{},
+		repositoryTeams:         map[int64]map[int64]string{},
+		repositoryRoles:         map[int64]map[int64]string{},
+		customRepoRoles:         map[int64][]github.CustomRepoRoles{},
+		orgRoles:                map[int64][]github.CustomOrgRoles{},
+		orgRoleUsers:            map[int64]mapset.Set[int64]{},
+		invitations:             map[int64][]github.Invitation{},
+		auditLog:                map[int64][]github.AuditEntry{},
+		enterpriseAuditLog:      map[string][]github.AuditEntry{},
+		lastActive:              map[string]time.Time{},
+		scimUsers:               map[string]bool{},
+		twoFactorDisabled:       mapset.NewSet[int64](),
+		orgMemberRoles:          map[int64]string{},
+		viewerOrgRoles:          map[int64]string{},
+		billingManagers:         map[int64]mapset.Set[int64]{},
+		securityManagerTeams:    mapset.NewSet[int64](),
+		idpGroups:               map[string]github.IDPGroup{},
+		teamIDPGroups:           map[int64][]string{},
 		organizations:           map[int64]github.Organization{},
 		repositories:            map[int64]github.Repository{},
 		teams:                   map[int64]github.Team{},
@@ -43,7 +82,8 @@ func parseUrlVariables(template, url string) map[string]string {
 	urlParts := strings.Split(url, "/")
 	for i, part := range strings.Split(template, "/") {
 		if variablesRegex.MatchString(part) {
-			key := strings.Trim(part, "{}")
+			// Variables may be constrained by a regex, e.g. {id:[0-9]+}.
+			key, _, _ := strings.Cut(strings.Trim(part, "{}"), ":")
 			output[key] = urlParts[i]
 		}
 	}
@@ -66,6 +106,9 @@ func parseBodyVariables(request *http.Request) map[string]string {
 			output[key] = castedValue
 		case float64:
 			output[key] = strconv.Itoa(int(castedValue))
+		case []interface{}:
+			// Lists are passed on as JSON.
+			output[key] = string(mock.MustMarshal(castedValue))
 		default:
 			// Skip other types.
 			continue
@@ -74,6 +117,14 @@ func parseBodyVariables(request *http.Request) map[string]string {
 	return output
 }
 
+func parseQueryVariables(request *http.Request) map[string]string {
+	output := make(map[string]string)
+	for key, values := range request.URL.Query() {
+		output[key] = values[0]
+	}
+	return output
+}
+
 func combineMaps(input ...map[string]string) map[string]string {
 	output := make(map[string]string)
 	for _, inputMap := range input {
@@ -108,133 +159,923 @@ func (mgh MockGitHub) Seed() (
 		Name:  &organizationName,
 		Login: &organizationSlug,
 	}
-	githubUser := github.User{
-		ID:    &userId,
-		Login: &userIdStr,
-		Email: &email,
-		Permissions: map[string]bool{
-			"permission0": true,
-		},
+	githubUser := github.User{
+		ID:    &userId,
+		Login: &userIdStr,
+		Email: &email,
+		Permissions: map[string]bool{
+			"permission0": true,
+		},
+	}
+	githubRepository := github.Repository{
+		ID:           &repositoryId,
+		Owner:        &githubUser,
+		Name:         &repositoryName,
+		Organization: &githubOrganization,
+	}
+	githubTeam := github.Team{
+		ID:           &teamId,
+		Slug:         github.String(fmt.Sprintf("team-%d", teamId)),
+		Organization: &githubOrganization,
+	}
+
+	mgh.organizations[organizationId] = githubOrganization
+	mgh.repositories[repositoryId] = githubRepository
+	mgh.teams[teamId] = githubTeam
+	mgh.users[userId] = githubUser
+	mgh.teamMemberships[teamId] = mapset.NewSet[int64](userId)
+	mgh.organizationMemberships[organizationId] = mapset.NewSet[int64](userId)
+	mgh.outsideCollaborators[organizationId] = mapset.NewSet[int64]()
+	mgh.repositoryMemberships[repositoryId] = mapset.NewSet[int64]()
+
+	return &githubOrganization, &githubRepository, &githubTeam, &githubUser, nil
+}
+
+// SetOrgRole sets the role of a user in the organizations they are a member of. Users are admins unless set otherwise.
+func (mgh MockGitHub) SetOrgRole(userId int64, role string) {
+	mgh.orgMemberRoles[userId] = role
+}
+
+// SetViewerOrgRole sets the role of the authenticated user in an organization. They are an admin unless set otherwise.
+func (mgh MockGitHub) SetViewerOrgRole(organizationId int64, role string) {
+	mgh.viewerOrgRoles[organizationId] = role
+}
+
+// AddBillingManager adds a user who manages the billing of the organization without being a member.
+func (mgh MockGitHub) AddBillingManager(organizationId int64, userId int64) *github.User {
+	githubUser, ok := mgh.users[userId]
+	if !ok {
+		userIdStr := strconv.FormatInt(userId, 10)
+		githubUser = github.User{
+			ID:    &userId,
+			Login: &userIdStr,
+		}
+		mgh.users[userId] = githubUser
+	}
+
+	if _, ok := mgh.billingManagers[organizationId]; !ok {
+		mgh.billingManagers[organizationId] = mapset.NewSet[int64]()
+	}
+	mgh.billingManagers[organizationId].Add(userId)
+
+	return &githubUser
+}
+
+// IsSecurityManagerTeam returns true if the team has the security manager role.
+func (mgh MockGitHub) IsSecurityManagerTeam(teamId int64) bool {
+	return mgh.securityManagerTeams.Contains(teamId)
+}
+
+// AddRepository adds a repository to the organization.
+func (mgh MockGitHub) AddRepository(organizationId int64, repository github.Repository) *github.Repository {
+	organization := mgh.organizations[organizationId]
+	repository.Organization = &organization
+
+	mgh.repositories[repository.GetID()] = repository
+	mgh.repositoryMemberships[repository.GetID()] = mapset.NewSet[int64]()
+
+	return &repository
+}
+
+// AddOrganization adds another organization with the given members. The login has to end with the organization ID.
+func (mgh MockGitHub) AddOrganization(organizationId int64, login string, memberIds ...int64) *github.Organization {
+	githubOrganization := github.Organization{
+		ID:    &organizationId,
+		Name:  github.String(fmt.Sprintf("organization #%d", organizationId)),
+		Login: &login,
+	}
+
+	mgh.organizations[organizationId] = githubOrganization
+	mgh.organizationMemberships[organizationId] = mapset.NewSet[int64](memberIds...)
+	mgh.outsideCollaborators[organizationId] = mapset.NewSet[int64]()
+
+	return &githubOrganization
+}
+
+// AddMember adds a user to the mock database that is a member of the organization.
+func (mgh MockGitHub) AddMember(organizationId int64, userId int64) *github.User {
+	githubUser, ok := mgh.users[userId]
+	if !ok {
+		userIdStr := strconv.FormatInt(userId, 10)
+		githubUser = github.User{
+			ID:    &userId,
+			Login: &userIdStr,
+		}
+		mgh.users[userId] = githubUser
+	}
+
+	if _, ok := mgh.organizationMemberships[organizationId]; !ok {
+		mgh.organizationMemberships[organizationId] = mapset.NewSet[int64]()
+	}
+	mgh.organizationMemberships[organizationId].Add(userId)
+
+	return &githubUser
+}
+
+// AddOutsideCollaborator adds a user to the mock database that has access to the organization's repositories without
+// being a member of the organization. The user can be a member of another organization.
+func (mgh MockGitHub) AddOutsideCollaborator(organizationId int64, userId int64) *github.User {
+	githubUser, ok := mgh.users[userId]
+	if !ok {
+		userIdStr := strconv.FormatInt(userId, 10)
+		githubUser = github.User{
+			ID:    &userId,
+			Login: &userIdStr,
+		}
+		mgh.users[userId] = githubUser
+	}
+
+	if _, ok := mgh.outsideCollaborators[organizationId]; !ok {
+		mgh.outsideCollaborators[organizationId] = mapset.NewSet[int64]()
+	}
+	mgh.outsideCollaborators[organizationId].Add(userId)
+
+	return &githubUser
+}
+
+// AddChildTeam adds a team that is nested under the parent team, in the same organization.
+func (mgh MockGitHub) AddChildTeam(parentTeamId int64, teamId int64) *github.Team {
+	parentTeam := mgh.teams[parentTeamId]
+	team := github.Team{
+		ID:           &teamId,
+		Name:         github.String(fmt.Sprintf("team-%d", teamId)),
+		Slug:         github.String(fmt.Sprintf("team-%d", teamId)),
+		Organization: parentTeam.Organization,
+		Parent:       &parentTeam,
+	}
+	mgh.teams[teamId] = team
+	mgh.teamMemberships[teamId] = mapset.NewSet[int64]()
+
+	return &team
+}
+
+// AddIDPGroup adds a group of the identity provider that teams can be synchronized with.
+func (mgh MockGitHub) AddIDPGroup(groupId string, groupName string) *github.IDPGroup {
+	group := github.IDPGroup{
+		GroupID:          github.String(groupId),
+		GroupName:        github.String(groupName),
+		GroupDescription: github.String(fmt.Sprintf("The %s group", groupName)),
+	}
+	mgh.idpGroups[groupId] = group
+
+	return &group
+}
+
+// ConnectIDPGroup synchronizes the membership of a team with an IdP group.
+func (mgh MockGitHub) ConnectIDPGroup(teamId int64, groupId string) {
+	mgh.teamIDPGroups[teamId] = append(mgh.teamIDPGroups[teamId], groupId)
+}
+
+// AddRepositoryTeam gives a team the permission on a repository.
+func (mgh MockGitHub) AddRepositoryTeam(repositoryId int64, teamId int64, permission string) {
+	if _, ok := mgh.repositoryTeams[repositoryId]; !ok {
+		mgh.repositoryTeams[repositoryId] = map[int64]string{}
+	}
+	mgh.repositoryTeams[repositoryId][teamId] = permission
+}
+
+// AddCustomRepoRole defines a custom repository role for an organization.
+func (mgh MockGitHub) AddCustomRepoRole(organizationId int64, name string) {
+	roleId := int64(len(mgh.customRepoRoles[organizationId]) + 1)
+	mgh.customRepoRoles[organizationId] = append(mgh.customRepoRoles[organizationId], github.CustomRepoRoles{
+		ID:       &roleId,
+		Name:     github.String(name),
+		BaseRole: github.String("read"),
+	})
+}
+
+// AddOrgRole defines an organization role for an organization and returns its ID.
+func (mgh MockGitHub) AddOrgRole(organizationId int64, name string) int64 {
+	roleId := int64(len(mgh.orgRoleUsers) + 1)
+	mgh.orgRoles[organizationId] = append(mgh.orgRoles[organizationId], github.CustomOrgRoles{
+		ID:   &roleId,
+		Name: github.String(name),
+	})
+	mgh.orgRoleUsers[roleId] = mapset.NewSet[int64]()
+
+	return roleId
+}
+
+// AddInvitation adds a pending invitation to an organization. Invitations to an email don't have a login.
+func (mgh MockGitHub) AddInvitation(organizationId int64, invitationId int64, login string, email string, role string) *github.Invitation {
+	invitation := github.Invitation{
+		ID:   &invitationId,
+		Role: github.String(role),
+	}
+	if login != "" {
+		invitation.Login = github.String(login)
+	}
+	if email != "" {
+		invitation.Email = github.String(email)
+	}
+	mgh.invitations[organizationId] = append(mgh.invitations[organizationId], invitation)
+
+	return &invitation
+}
+
+// IsSCIMUserActive returns whether a user provisioned through SCIM is active, and whether the user exists. SCIM user
+// IDs are "scim-" followed by the user name.
+func (mgh MockGitHub) IsSCIMUserActive(scimUserId string) (bool, bool) {
+	active, ok := mgh.scimUsers[scimUserId]
+	return active, ok
+}
+
+// AddAuditLogEntry adds an entry to the audit log of an organization, where the user was affected by the action.
+func (mgh MockGitHub) AddAuditLogEntry(
+	organizationId int64,
+	action string,
+	userId int64,
+	occurredAt time.Time,
+	fields map[string]interface{},
+) *github.AuditEntry {
+	documentId := fmt.Sprintf("document-%d", len(mgh.auditLog[organizationId]))
+	entry := github.AuditEntry{
+		Action:           github.String(action),
+		DocumentID:       &documentId,
+		OrgID:            &organizationId,
+		ActorID:          &userId,
+		UserID:           &userId,
+		Timestamp:        &github.Timestamp{Time: occurredAt},
+		AdditionalFields: fields,
+	}
+	mgh.auditLog[organizationId] = append(mgh.auditLog[organizationId], entry)
+
+	return &entry
+}
+
+// DisableTwoFactor marks a user as not having two-factor authentication enabled.
+func (mgh MockGitHub) DisableTwoFactor(userId int64) {
+	mgh.twoFactorDisabled.Add(userId)
+}
+
+// SetUserLastActive sets when the user was last active in the all users report of GitHub Enterprise Server.
+func (mgh MockGitHub) SetUserLastActive(login string, lastActive time.Time) {
+	mgh.lastActive[login] = lastActive
+}
+
+// AddUserLogin adds a sign in of the user to the audit log of an enterprise.
+func (mgh MockGitHub) AddUserLogin(enterprise string, login string, occurredAt time.Time) *github.AuditEntry {
+	documentId := fmt.Sprintf("document-%d", len(mgh.enterpriseAuditLog[enterprise]))
+	entry := github.AuditEntry{
+		Action:     github.String("user.login"),
+		Actor:      github.String(login),
+		DocumentID: &documentId,
+		Timestamp:  &github.Timestamp{Time: occurredAt},
+	}
+	mgh.enterpriseAuditLog[enterprise] = append(mgh.enterpriseAuditLog[enterprise], entry)
+
+	return &entry
+}
+
+func getResource[T interface{}](
+	w http.ResponseWriter,
+	idStr string,
+	table map[int64]T,
+) (*T, error) {
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return nil, err
+	}
+	object, ok := table[id]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return nil, err
+	}
+
+	return &object, nil
+}
+
+func writeResource[T interface{}](
+	w http.ResponseWriter,
+	idStr string,
+	table map[int64]T,
+) {
+	object, err := getResource(w, idStr, table)
+	if err == nil {
+		_, _ = w.Write(mock.MustMarshal(object))
+	}
+}
+
+func (mgh MockGitHub) getUsers(
+	w http.ResponseWriter,
+	variables map[string]string,
+) {
+	mgh.getUsersFromCrossTable(
+		w,
+		variables,
+		mgh.organizationMemberships,
+		"org",
+	)
+}
+
+func (mgh MockGitHub) getUser(
+	w http.ResponseWriter,
+	variables map[string]string,
+) {
+	if id, ok := variables["id"]; ok {
+		writeResource(w, id, mgh.users)
+	}
+}
+
+func (mgh MockGitHub) addUser(
+	w http.ResponseWriter,
+	variables map[string]string,
+) {
+	// Invitations to an email stay pending, as there is no user to add until the invitation is accepted.
+	if email, ok := variables["email"]; ok {
+		organizationId, err := getCrossTableId(w, variables, "org")
+		if err != nil {
+			return
+		}
+		invitationId := int64(2000 + len(mgh.invitations[organizationId]))
+		invitation := mgh.AddInvitation(organizationId, invitationId, "", email, variables["role"])
+
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write(mock.MustMarshal(invitation))
+		return
+	}
+
+	// Billing managers aren't members of the organization.
+	if variables["role"] == "billing_manager" {
+		organizationId, err := getCrossTableId(w, variables, "org")
+		if err != nil {
+			return
+		}
+		userId, err := getUserId(w, variables)
+		if err != nil {
+			return
+		}
+		mgh.AddBillingManager(organizationId, userId)
+
+		w.WriteHeader(http.StatusCreated)
+		return
+	}
+
+	mgh.addUserToCrossTable(
+		w,
+		variables,
+		mgh.organizationMemberships,
+		"org",
+	)
+}
+
+func (mgh MockGitHub) removeUser(
+	w http.ResponseWriter,
+	variables map[string]string,
+) {
+	userId, err := getUserId(w, variables)
+	if err != nil {
+		return
+	}
+	organizationId, err := getCrossTableId(w, variables, "org")
+	if err != nil {
+		return
+	}
+	if mgh.isBillingManager(organizationId, userId) {
+		mgh.billingManagers[organizationId].Remove(userId)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	mgh.removeUserFromCrossTable(
+		w,
+		variables,
+		mgh.organizationMemberships,
+		"org",
+	)
+}
+
+// isBillingManager returns whether the user is a billing manager of the organization.
+func (mgh MockGitHub) isBillingManager(organizationId int64, userId int64) bool {
+	billingManagers, ok := mgh.billingManagers[organizationId]
+	return ok && billingManagers.Contains(userId)
+}
+
+func (mgh MockGitHub) getBillingManagers(
+	w http.ResponseWriter,
+	variables map[string]string,
+) {
+	organizationId, err := getCrossTableId(w, variables, "org")
+	if err != nil {
+		return
+	}
+	if _, ok := mgh.billingManagers[organizationId]; !ok {
+		_, _ = w.Write(mock.MustMarshal([]github.User{}))
+		return
+	}
+
+	mgh.getUsersFromCrossTable(w, variables, mgh.billingManagers, "org")
+}
+
+func (mgh MockGitHub) getOutsideCollaborators(
+	w http.ResponseWriter,
+	variables map[string]string,
+) {
+	mgh.getUsersFromCrossTable(
+		w,
+		variables,
+		mgh.outsideCollaborators,
+		"org",
+	)
+}
+
+func (mgh MockGitHub) removeOutsideCollaborator(
+	w http.ResponseWriter,
+	variables map[string]string,
+) {
+	mgh.removeUserFromCrossTable(
+		w,
+		variables,
+		mgh.outsideCollaborators,
+		"org",
+	)
+}
+
+func (mgh MockGitHub) getCustomRepoRoles(
+	w http.ResponseWriter,
+	variables map[string]string,
+) {
+	organizationId, err := getCrossTableId(w, variables, "org")
+	if err != nil {
+		return
+	}
+
+	roles := make([]*github.CustomRepoRoles, 0)
+	for _, role := range mgh.customRepoRoles[organizationId] {
+		role := role
+		roles = append(roles, &role)
+	}
+
+	_, _ = w.Write(mock.MustMarshal(github.OrganizationCustomRepoRoles{
+		TotalCount:      github.Int(len(roles)),
+		CustomRepoRoles: roles,
+	}))
+}
+
+func (mgh MockGitHub) getOrgRoles(
+	w http.ResponseWriter,
+	variables map[string]string,
+) {
+	organizationId, err := getCrossTableId(w, variables, "org")
+	if err != nil {
+		return
+	}
+
+	roles := make([]*github.CustomOrgRoles, 0)
+	for _, role := range mgh.orgRoles[organizationId] {
+		role := role
+		roles = append(roles, &role)
+	}
+
+	_, _ = w.Write(mock.MustMarshal(github.OrganizationCustomRoles{
+		TotalCount:      github.Int(len(roles)),
+		CustomRepoRoles: roles,
+	}))
+}
+
+func (mgh MockGitHub) getOrgRoleUsers(
+	w http.ResponseWriter,
+	variables map[string]string,
+) {
+	mgh.getUsersFromCrossTable(
+		w,
+		variables,
+		mgh.orgRoleUsers,
+		"role_id",
+	)
+}
+
+func (mgh MockGitHub) getOrgRoleTeams(
+	w http.ResponseWriter,
+	_ map[string]string,
+) {
+	_, _ = w.Write(mock.MustMarshal([]github.Team{}))
+}
+
+func (mgh MockGitHub) addOrgRoleUser(
+	w http.ResponseWriter,
+	variables map[string]string,
+) {
+	mgh.addUserToCrossTable(
+		w,
+		variables,
+		mgh.orgRoleUsers,
+		"role_id",
+	)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (mgh MockGitHub) removeOrgRoleUser(
+	w http.ResponseWriter,
+	variables map[string]string,
+) {
+	mgh.removeUserFromCrossTable(
+		w,
+		variables,
+		mgh.orgRoleUsers,
+		"role_id",
+	)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (mgh MockGitHub) getInvitations(
+	w http.ResponseWriter,
+	variables map[string]string,
+) {
+	organizationId, err := getCrossTableId(w, variables, "org")
+	if err != nil {
+		return
+	}
+
+	invitations := make([]github.Invitation, 0)
+	invitations = append(invitations, mgh.invitations[organizationId]...)
+
+	_, _ = w.Write(mock.MustMarshal(invitations))
+}
+
+func (mgh MockGitHub) cancelInvitation(
+	w http.ResponseWriter,
+	variables map[string]string,
+) {
+	organizationId, err := getCrossTableId(w, variables, "org")
+	if err != nil {
+		return
+	}
+	invitationId, err := getCrossTableId(w, variables, "invitation_id")
+	if err != nil {
+		return
+	}
+
+	for i, invitation := range mgh.invitations[organizationId] {
+		if invitation.GetID() == invitationId {
+			mgh.invitations[organizationId] = append(mgh.invitations[organizationId][:i], mgh.invitations[organizationId][i+1:]...)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusNotFound)
+}
+
+func (mgh MockGitHub) createSCIMUser(
+	w http.ResponseWriter,
+	variables map[string]string,
+) {
+	userName, ok := variables["userName"]
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	scimUserId := "scim-" + userName
+	mgh.scimUsers[scimUserId] = true
+
+	w.WriteHeader(http.StatusCreated)
+	_, _ = w.Write(mock.MustMarshal(map[string]interface{}{
+		"id":       scimUserId,
+		"userName": userName,
+		"active":   true,
+	}))
+}
+
+// suspendSCIMUser handles the only SCIM patch the connector sends, which sets the user inactive.
+func (mgh MockGitHub) suspendSCIMUser(
+	w http.ResponseWriter,
+	variables map[string]string,
+) {
+	scimUserId := variables["scim_user_id"]
+	if _, ok := mgh.scimUsers[scimUserId]; !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	mgh.scimUsers[scimUserId] = false
+	_, _ = w.Write(mock.MustMarshal(map[string]interface{}{
+		"id":     scimUserId,
+		"active": false,
+	}))
+}
+
+func (mgh MockGitHub) getAuditLog(
+	w http.ResponseWriter,
+	variables map[string]string,
+) {
+	organizationId, err := getCrossTableId(w, variables, "org")
+	if err != nil {
+		return
+	}
+
+	writeAuditLog(w, mgh.auditLog[organizationId], variables)
+}
+
+func (mgh MockGitHub) getEnterpriseAuditLog(
+	w http.ResponseWriter,
+	variables map[string]string,
+) {
+	writeAuditLog(w, mgh.enterpriseAuditLog[variables["enterprise"]], variables)
+}
+
+// getAllUsersReport writes the all users report of GitHub Enterprise Server, where users who were never active have
+// no last active time.
+func (mgh MockGitHub) getAllUsersReport(
+	w http.ResponseWriter,
+	_ map[string]string,
+) {
+	records := [][]string{{"created_at", "id", "login", "email", "role", "suspended?", "last_active"}}
+	for _, user := range mgh.users {
+		var lastActive string
+		if t, ok := mgh.lastActive[user.GetLogin()]; ok {
+			lastActive = t.UTC().Format("2006-01-02 15:04:05 MST")
+		}
+		records = append(records, []string{
+			"2020-01-01 00:00:00 UTC",
+			strconv.FormatInt(user.GetID(), 10),
+			user.GetLogin(),
+			user.GetEmail(),
+			"user",
+			"false",
+			lastActive,
+		})
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	_ = csv.NewWriter(w).WriteAll(records)
+}
+
+// writeAuditLog writes the audit log entries that match the search phrase.
+func writeAuditLog(
+	w http.ResponseWriter,
+	auditLog []github.AuditEntry,
+	variables map[string]string,
+) {
+	// The search phrase is a list of qualifiers, of which created, actor and action are supported.
+	var since time.Time
+	var actor, action string
+	for _, qualifier := range strings.Fields(variables["phrase"]) {
+		key, value, _ := strings.Cut(qualifier, ":")
+		switch key {
+		case "created":
+			var err error
+			since, err = time.Parse(time.RFC3339, strings.TrimPrefix(value, ">="))
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+		case "actor":
+			actor = value
+		case "action":
+			action = value
+		}
+	}
+
+	entries := make([]*github.AuditEntry, 0)
+	for _, entry := range auditLog {
+		entry := entry
+		if entry.GetTimestamp().Before(since) {
+			continue
+		}
+		if actor != "" && entry.GetActor() != actor {
+			continue
+		}
+		if action != "" && entry.GetAction() != action {
+			continue
+		}
+		entries = append(entries, &entry)
+	}
+
+	if variables["order"] == "desc" {
+		slices.Reverse(entries)
+	}
+	if perPage, err := strconv.Atoi(variables["per_page"]); err == nil && perPage < len(entries) {
+		entries = entries[:perPage]
+	}
+
+	_, _ = w.Write(mock.MustMarshal(entries))
+}
+
+func (mgh MockGitHub) getOrganization(
+	w http.ResponseWriter,
+	variables map[string]string,
+) {
+	if id, ok := variables["org_id"]; ok {
+		writeResource(w, id, mgh.organizations)
+	}
+}
+
+func (mgh MockGitHub) getOrganizationByLogin(
+	w http.ResponseWriter,
+	variables map[string]string,
+) {
+	organizationId, err := getCrossTableId(w, variables, "org")
+	if err != nil {
+		return
+	}
+	writeResource(w, strconv.FormatInt(organizationId, 10), mgh.organizations)
+}
+
+func (mgh MockGitHub) getTeamBySlug(
+	w http.ResponseWriter,
+	variables map[string]string,
+) {
+	teamId, err := getCrossTableId(w, variables, "team_slug")
+	if err != nil {
+		return
 	}
-	githubRepository := github.Repository{
-		ID:           &repositoryId,
-		Owner:        &githubUser,
-		Name:         &repositoryName,
-		Organization: &githubOrganization,
+	writeResource(w, strconv.FormatInt(teamId, 10), mgh.teams)
+}
+
+func (mgh MockGitHub) getIDPGroups(
+	w http.ResponseWriter,
+	_ map[string]string,
+) {
+	groups := make([]*github.IDPGroup, 0)
+	for _, group := range mgh.idpGroups {
+		group := group
+		groups = append(groups, &group)
 	}
-	githubTeam := github.Team{
-		ID:           &teamId,
-		Organization: &githubOrganization,
+
+	_, _ = w.Write(mock.MustMarshal(github.IDPGroupList{Groups: groups}))
+}
+
+func (mgh MockGitHub) writeTeamIDPGroups(w http.ResponseWriter, teamId int64) {
+	groups := make([]*github.IDPGroup, 0)
+	for _, groupId := range mgh.teamIDPGroups[teamId] {
+		group := mgh.idpGroups[groupId]
+		groups = append(groups, &group)
 	}
 
-	mgh.organizations[organizationId] = githubOrganization
-	mgh.repositories[repositoryId] = githubRepository
-	mgh.teams[teamId] = githubTeam
-	mgh.users[userId] = githubUser
-	mgh.teamMemberships[teamId] = mapset.NewSet[int64](userId)
-	mgh.organizationMemberships[organizationId] = mapset.NewSet[int64](userId)
+	_, _ = w.Write(mock.MustMarshal(github.IDPGroupList{Groups: groups}))
+}
 
-	return &githubOrganization, &githubRepository, &githubTeam, &githubUser, nil
+func (mgh MockGitHub) getTeamIDPGroups(
+	w http.ResponseWriter,
+	variables map[string]string,
+) {
+	teamId, err := getCrossTableId(w, variables, "team_slug")
+	if err != nil {
+		return
+	}
+	mgh.writeTeamIDPGroups(w, teamId)
 }
 
-func getResource[T interface{}](
+func (mgh MockGitHub) updateTeamIDPGroups(
 	w http.ResponseWriter,
-	idStr string,
-	table map[int64]T,
-) (*T, error) {
-	id, err := strconv.ParseInt(idStr, 10, 64)
+	variables map[string]string,
+) {
+	teamId, err := getCrossTableId(w, variables, "team_slug")
+	if err != nil {
+		return
+	}
+
+	var groups []github.IDPGroup
+	err = json.Unmarshal([]byte(variables["groups"]), &groups)
 	if err != nil {
 		w.WriteHeader(http.StatusBadRequest)
-		return nil, err
+		return
 	}
-	object, ok := table[id]
-	if !ok {
-		w.WriteHeader(http.StatusNotFound)
-		return nil, err
+
+	groupIds := make([]string, 0, len(groups))
+	for _, group := range groups {
+		if _, ok := mgh.idpGroups[group.GetGroupID()]; !ok {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			return
+		}
+		groupIds = append(groupIds, group.GetGroupID())
 	}
+	mgh.teamIDPGroups[teamId] = groupIds
 
-	return &object, nil
+	mgh.writeTeamIDPGroups(w, teamId)
 }
 
-func writeResource[T interface{}](
+func (mgh MockGitHub) getSecurityManagerTeams(
 	w http.ResponseWriter,
-	idStr string,
-	table map[int64]T,
+	_ map[string]string,
 ) {
-	object, err := getResource(w, idStr, table)
-	if err == nil {
-		_, _ = w.Write(mock.MustMarshal(object))
+	teams := make([]github.Team, 0)
+	for _, teamId := range mgh.securityManagerTeams.ToSlice() {
+		if team, ok := mgh.teams[teamId]; ok {
+			teams = append(teams, team)
+		}
 	}
+
+	_, _ = w.Write(mock.MustMarshal(teams))
 }
 
-func (mgh MockGitHub) getUsers(
+func (mgh MockGitHub) addSecurityManagerTeam(
 	w http.ResponseWriter,
 	variables map[string]string,
 ) {
-	mgh.getUsersFromCrossTable(
-		w,
-		variables,
-		mgh.organizationMemberships,
-		"org",
-	)
+	teamId, err := getCrossTableId(w, variables, "team_slug")
+	if err != nil {
+		return
+	}
+	if _, ok := mgh.teams[teamId]; !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	mgh.securityManagerTeams.Add(teamId)
+	w.WriteHeader(http.StatusNoContent)
 }
 
-func (mgh MockGitHub) getUser(
+func (mgh MockGitHub) removeSecurityManagerTeam(
 	w http.ResponseWriter,
 	variables map[string]string,
 ) {
-	if id, ok := variables["id"]; ok {
-		writeResource(w, id, mgh.users)
+	teamId, err := getCrossTableId(w, variables, "team_slug")
+	if err != nil {
+		return
 	}
+	mgh.securityManagerTeams.Remove(teamId)
+	w.WriteHeader(http.StatusNoContent)
 }
 
-func (mgh MockGitHub) addUser(
+func (mgh MockGitHub) getRepository(
 	w http.ResponseWriter,
 	variables map[string]string,
 ) {
-	mgh.addUserToCrossTable(
-		w,
-		variables,
-		mgh.organizationMemberships,
-		"org",
-	)
+	if id, ok := variables["repository_id"]; ok {
+		writeResource(w, id, mgh.repositories)
+	}
 }
 
-func (mgh MockGitHub) removeUser(
+func (mgh MockGitHub) getRepositoryByName(
 	w http.ResponseWriter,
 	variables map[string]string,
 ) {
-	mgh.removeUserFromCrossTable(
-		w,
-		variables,
-		mgh.organizationMemberships,
-		"org",
-	)
+	for _, repository := range mgh.repositories {
+		if repository.GetOrganization().GetLogin() == variables["owner"] && repository.GetName() == variables["repo"] {
+			_, _ = w.Write(mock.MustMarshal(repository))
+			return
+		}
+	}
+	w.WriteHeader(http.StatusNotFound)
 }
 
-func (mgh MockGitHub) getOrganization(
+func (mgh MockGitHub) getRepositories(
 	w http.ResponseWriter,
 	variables map[string]string,
 ) {
-	if id, ok := variables["org_id"]; ok {
-		writeResource(w, id, mgh.organizations)
+	organizationId, err := getCrossTableId(w, variables, "org")
+	if err != nil {
+		return
+	}
+
+	repositories := make([]github.Repository, 0)
+	for _, repository := range mgh.repositories {
+		if repository.GetOrganization().GetID() == organizationId {
+			repositories = append(repositories, repository)
+		}
 	}
+	slices.SortFunc(repositories, func(a, b github.Repository) int {
+		return int(a.GetID() - b.GetID())
+	})
+
+	_, _ = w.Write(mock.MustMarshal(repositories))
 }
 
-func (mgh MockGitHub) getRepository(
+func (mgh MockGitHub) getTeams(
 	w http.ResponseWriter,
 	variables map[string]string,
 ) {
-	if id, ok := variables["repository_id"]; ok {
-		writeResource(w, id, mgh.repositories)
+	organizationId, err := getCrossTableId(w, variables, "org")
+	if err != nil {
+		return
+	}
+
+	teams := make([]github.Team, 0)
+	for _, team := range mgh.teams {
+		if team.GetOrganization().GetID() == organizationId {
+			teams = append(teams, team)
+		}
 	}
+	slices.SortFunc(teams, func(a, b github.Team) int {
+		return int(a.GetID() - b.GetID())
+	})
+
+	_, _ = w.Write(mock.MustMarshal(teams))
 }
 
 func (mgh MockGitHub) getRepositoryTeams(
 	w http.ResponseWriter,
 	variables map[string]string,
 ) {
-	// TODO(marcos): Implement granting repositories to teams.
+	repositoryId, err := getCrossTableId(w, variables, "repo")
+	if err != nil {
+		return
+	}
+
+	teams := make([]github.Team, 0)
+	for teamId, permission := range mgh.repositoryTeams[repositoryId] {
+		team, ok := mgh.teams[teamId]
+		if !ok {
+			continue
+		}
+		team.Permission = github.String(permission)
+		team.Permissions = map[string]bool{permission: true}
+		teams = append(teams, team)
+	}
+
+	_, _ = w.Write(mock.MustMarshal(teams))
 }
 
 func (mgh MockGitHub) getTeam(
@@ -246,6 +1087,122 @@ func (mgh MockGitHub) getTeam(
 	}
 }
 
+func (mgh MockGitHub) getOrganizations(
+	w http.ResponseWriter,
+	_ map[string]string,
+) {
+	organizations := make([]github.Organization, 0)
+	for _, organization := range mgh.organizations {
+		organizations = append(organizations, organization)
+	}
+
+	_, _ = w.Write(mock.MustMarshal(organizations))
+}
+
+func (mgh MockGitHub) createTeam(
+	w http.ResponseWriter,
+	variables map[string]string,
+) {
+	organizationId, err := getCrossTableId(w, variables, "org")
+	if err != nil {
+		return
+	}
+	organization, ok := mgh.organizations[organizationId]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	teamId := int64(1000 + len(mgh.teams))
+	team := github.Team{
+		ID:           &teamId,
+		Name:         github.String(variables["name"]),
+		Slug:         github.String(fmt.Sprintf("team-%d", teamId)),
+		Organization: &organization,
+	}
+	if description, ok := variables["description"]; ok {
+		team.Description = github.String(description)
+	}
+	if privacy, ok := variables["privacy"]; ok {
+		team.Privacy = github.String(privacy)
+	}
+	mgh.teams[teamId] = team
+	mgh.teamMemberships[teamId] = mapset.NewSet[int64]()
+
+	w.WriteHeader(http.StatusCreated)
+	_, _ = w.Write(mock.MustMarshal(team))
+}
+
+func (mgh MockGitHub) getChildTeams(
+	w http.ResponseWriter,
+	variables map[string]string,
+) {
+	parentTeamId, err := getCrossTableId(w, variables, "team_id")
+	if err != nil {
+		return
+	}
+
+	teams := make([]github.Team, 0)
+	for _, team := range mgh.teams {
+		if team.GetParent().GetID() == parentTeamId {
+			teams = append(teams, team)
+		}
+	}
+
+	_, _ = w.Write(mock.MustMarshal(teams))
+}
+
+func (mgh MockGitHub) editTeam(
+	w http.ResponseWriter,
+	variables map[string]string,
+) {
+	teamId, err := getCrossTableId(w, variables, "team_id")
+	if err != nil {
+		return
+	}
+	team, ok := mgh.teams[teamId]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	team.Parent = nil
+	if parentTeamIdStr, ok := variables["parent_team_id"]; ok {
+		parentTeamId, err := strconv.ParseInt(parentTeamIdStr, 10, 64)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		parentTeam, ok := mgh.teams[parentTeamId]
+		if !ok {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			return
+		}
+		team.Parent = &parentTeam
+	}
+	mgh.teams[teamId] = team
+
+	_, _ = w.Write(mock.MustMarshal(team))
+}
+
+func (mgh MockGitHub) deleteTeam(
+	w http.ResponseWriter,
+	variables map[string]string,
+) {
+	teamId, err := getCrossTableId(w, variables, "team_id")
+	if err != nil {
+		return
+	}
+	if _, ok := mgh.teams[teamId]; !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	delete(mgh.teams, teamId)
+	delete(mgh.teamMemberships, teamId)
+	w.WriteHeader(http.StatusNoContent)
+}
+
 func (mgh MockGitHub) getUsersFromCrossTable(
 	w http.ResponseWriter,
 	variables map[string]string,
@@ -261,6 +1218,9 @@ func (mgh MockGitHub) getUsersFromCrossTable(
 
 	users := make([]github.User, 0)
 	for _, membership := range memberships.ToSlice() {
+		if variables["filter"] == "2fa_disabled" && !mgh.twoFactorDisabled.Contains(membership) {
+			continue
+		}
 		if user, ok := mgh.users[membership]; ok {
 			users = append(users, user)
 		}
@@ -402,8 +1362,59 @@ func (mgh MockGitHub) getMembership(
 ) {
 	userId, _ := getUserId(w, variables)
 	if user, ok := mgh.users[userId]; ok {
-		_, _ = w.Write(mock.MustMarshal(userToMembership(&user)))
+		membership := userToMembership(&user)
+		if role, ok := mgh.orgMemberRoles[userId]; ok {
+			membership.Role = github.String(role)
+		}
+		organizationId, _ := getCrossTableId(w, variables, "org")
+		if mgh.isBillingManager(organizationId, userId) {
+			membership.Role = github.String("billing_manager")
+		}
+		_, _ = w.Write(mock.MustMarshal(membership))
+	}
+}
+
+func (mgh MockGitHub) getViewerMembership(
+	w http.ResponseWriter,
+	variables map[string]string,
+) {
+	organizationId, err := getCrossTableId(w, variables, "org")
+	if err != nil {
+		return
+	}
+	organization, ok := mgh.organizations[organizationId]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	role, ok := mgh.viewerOrgRoles[organizationId]
+	if !ok {
+		role = "admin"
+	}
+
+	_, _ = w.Write(mock.MustMarshal(github.Membership{
+		Role:         github.String(role),
+		State:        github.String("active"),
+		Organization: &organization,
+	}))
+}
+
+func (mgh MockGitHub) editMembership(
+	w http.ResponseWriter,
+	variables map[string]string,
+) {
+	userId, err := getUserId(w, variables)
+	if err != nil {
+		return
 	}
+	if _, ok := mgh.users[userId]; !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	mgh.orgMemberRoles[userId] = variables["role"]
+
+	mgh.getMembership(w, variables)
 }
 
 func (mgh MockGitHub) getTeamMembership(
@@ -423,12 +1434,29 @@ func (mgh MockGitHub) getRepositoryCollaborators(
 	w http.ResponseWriter,
 	variables map[string]string,
 ) {
-	mgh.getUsersFromCrossTable(
-		w,
-		variables,
-		mgh.repositoryMemberships,
-		"repo",
-	)
+	repositoryId, err := getCrossTableId(w, variables, "repo")
+	if err != nil {
+		return
+	}
+	memberships, ok := mgh.repositoryMemberships[repositoryId]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	users := make([]github.User, 0)
+	for _, userId := range memberships.ToSlice() {
+		user, ok := mgh.users[userId]
+		if !ok {
+			continue
+		}
+		if roleName, ok := mgh.repositoryRoles[repositoryId][userId]; ok {
+			user.RoleName = github.String(roleName)
+		}
+		users = append(users, user)
+	}
+
+	_, _ = w.Write(mock.MustMarshal(users))
 }
 
 func (mgh MockGitHub) getRepositoryCollaborator(
@@ -468,6 +1496,13 @@ func (mgh MockGitHub) addRepositoryCollaborator(
 		mgh.repositoryMemberships,
 		"repo",
 	)
+
+	repositoryId, _ := getCrossTableId(w, variables, "repo")
+	userId, _ := getUserId(w, variables)
+	if _, ok := mgh.repositoryRoles[repositoryId]; !ok {
+		mgh.repositoryRoles[repositoryId] = map[int64]string{}
+	}
+	mgh.repositoryRoles[repositoryId][userId] = variables["permission"]
 }
 
 func (mgh MockGitHub) removeMembership(
@@ -513,6 +1548,7 @@ func addEndpointHandler(
 							endpoint.Pattern,
 							request.URL.String(),
 						),
+						parseQueryVariables(request),
 						parseBodyVariables(request),
 					),
 				)
@@ -524,18 +1560,52 @@ func addEndpointHandler(
 func (mgh MockGitHub) Server() *http.Client {
 	routesMap := map[mock.EndpointPattern]handler{
 		GetOrganizationById:                                                 mgh.getOrganization,
+		GetOrgsAuditLogByOrg:                                                mgh.getAuditLog,
+		GetEnterprisesAuditLogByEnterprise:                                  mgh.getEnterpriseAuditLog,
+		GetOrgsBillingManagersByOrg:                                         mgh.getBillingManagers,
+		GetStafftoolsReportsAllUsers:                                        mgh.getAllUsersReport,
+		GetOrgsCustomRepositoryRolesByOrg:                                   mgh.getCustomRepoRoles,
 		GetOrganizationsTeamsMembersByTeamId:                                mgh.getMembers,
 		GetOrganizationsTeamByTeamId:                                        mgh.getTeam,
 		GetOrganizationsTeamsMembershipsByTeamIdByUsername:                  mgh.getTeamMembership,
 		GetRepositoryById:                                                   mgh.getRepository,
+		mock.GetReposByOwnerByRepo:                                          mgh.getRepositoryByName,
 		GetUserById:                                                         mgh.getUser,
+		DeleteOrganizationsTeamByTeamId:                                     mgh.deleteTeam,
+		GetOrganizationsTeamsTeamsByTeamId:                                  mgh.getChildTeams,
+		PatchOrganizationsTeamByTeamId:                                      mgh.editTeam,
+		PostScimV2EnterprisesUsersByEnterprise:                              mgh.createSCIMUser,
+		PatchScimV2EnterprisesUsersByEnterpriseByScimUserId:                 mgh.suspendSCIMUser,
+		mock.GetUserOrgs:                                                    mgh.getOrganizations,
+		mock.GetOrgsByOrg:                                                   mgh.getOrganizationByLogin,
+		mock.GetOrgsTeamsByOrg:                                              mgh.getTeams,
+		mock.GetOrgsTeamsByOrgByTeamSlug:                                    mgh.getTeamBySlug,
+		GetOrgsTeamSyncGroupsByOrg:                                          mgh.getIDPGroups,
+		GetOrgsTeamsTeamSyncGroupMappingsByOrgByTeamSlug:                    mgh.getTeamIDPGroups,
+		PatchOrgsTeamsTeamSyncGroupMappingsByOrgByTeamSlug:                  mgh.updateTeamIDPGroups,
+		mock.PostOrgsTeamsByOrg:                                             mgh.createTeam,
+		mock.DeleteOrgsInvitationsByOrgByInvitationId:                       mgh.cancelInvitation,
 		mock.DeleteOrgsMembershipsByOrgByUsername:                           mgh.removeUser,
+		mock.DeleteOrgsOutsideCollaboratorsByOrgByUsername:                  mgh.removeOutsideCollaborator,
 		mock.DeleteReposCollaboratorsByOwnerByRepoByUsername:                mgh.removeRepositoryCollaborator,
+		mock.GetOrgsInvitationsByOrg:                                        mgh.getInvitations,
 		mock.GetOrgsMembersByOrg:                                            mgh.getUsers,
 		mock.GetOrgsMembershipsByOrgByUsername:                              mgh.getMembership,
+		mock.GetUserMembershipsOrgsByOrg:                                    mgh.getViewerMembership,
+		mock.PutOrgsMembershipsByOrgByUsername:                              mgh.editMembership,
+		mock.GetOrgsSecurityManagersByOrg:                                   mgh.getSecurityManagerTeams,
+		mock.PutOrgsSecurityManagersTeamsByOrgByTeamSlug:                    mgh.addSecurityManagerTeam,
+		mock.DeleteOrgsSecurityManagersTeamsByOrgByTeamSlug:                 mgh.removeSecurityManagerTeam,
+		mock.DeleteOrgsOrganizationRolesUsersByOrgByUsernameByRoleId:        mgh.removeOrgRoleUser,
+		mock.GetOrgsOrganizationRolesByOrg:                                  mgh.getOrgRoles,
+		mock.GetOrgsOrganizationRolesTeamsByOrgByRoleId:                     mgh.getOrgRoleTeams,
+		mock.GetOrgsOrganizationRolesUsersByOrgByRoleId:                     mgh.getOrgRoleUsers,
+		mock.GetOrgsOutsideCollaboratorsByOrg:                               mgh.getOutsideCollaborators,
+		mock.PutOrgsOrganizationRolesUsersByOrgByUsernameByRoleId:           mgh.addOrgRoleUser,
 		mock.GetReposCollaboratorsByOwnerByRepo:                             mgh.getRepositoryCollaborators,
 		mock.GetReposCollaboratorsByOwnerByRepoByUsername:                   mgh.getRepositoryCollaborator,
 		mock.GetReposTeamsByOwnerByRepo:                                     mgh.getRepositoryTeams,
+		mock.GetOrgsReposByOrg:                                              mgh.getRepositories,
 		mock.PostOrgsInvitationsByOrg:                                       mgh.addUser,
 		mock.PutReposCollaboratorsByOwnerByRepoByUsername:                   mgh.addRepositoryCollaborator,
 		DeleteOrganizationsTeamsMembershipsByOrganizationByTeamIdByUsername: mgh.removeMembership,
@@ -21,9 +21,30 @@ func MockGraphQL() *githubv4.Client {
 				writer.WriteHeader(http.StatusOK)
 
 				var filename string
-				if strings.Contains(string(b), "samlIdentityProvider{id}") {
+				switch query := string(b); {
+				case strings.Contains(query, "organizationVerifiedDomainEmails"):
+					filename = "../../test/mocks/fixtures/organization_verified_domain_emails.json"
+				case strings.Contains(query, "samlIdentityProvider{id}") && strings.Contains(query, "without-saml"):
+					filename = "../../test/mocks/fixtures/organization_without_saml.json"
+				case strings.Contains(query, "samlIdentityProvider{id}"):
 					filename = "../../test/mocks/fixtures/organization0.json"
-				} else {
+				case strings.Contains(query, "oidcProvider"):
+					filename = "../../test/mocks/fixtures/enterprise_external_identity.json"
+				case strings.Contains(query, "admins("):
+					filename = "../../test/mocks/fixtures/enterprise_owners.json"
+				case strings.Contains(query, "billingManagers("):
+					filename = "../../test/mocks/fixtures/enterprise_billing_managers.json"
+				case strings.Contains(query, `"hasTwoFactorEnabled":true`):
+					filename = "../../test/mocks/fixtures/enterprise_members_two_factor_enabled.json"
+				case strings.Contains(query, `"hasTwoFactorEnabled":false`):
+					filename = "../../test/mocks/fixtures/enterprise_members_two_factor_disabled.json"
+				case strings.Contains(query, "members("):
+					filename = "../../test/mocks/fixtures/enterprise_members.json"
+				case strings.Contains(query, "organizations("):
+					filename = "../../test/mocks/fixtures/enterprise_organizations.json"
+				case strings.Contains(query, "enterprise("):
+					filename = "../../test/mocks/fixtures/enterprise.json"
+				default:
 					filename = "../../test/mocks/fixtures/organization1.json"
 				}
 				data, _ := os.ReadFile(filename)
@@ -6,6 +6,8 @@ import (
 
 	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
 	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/stretchr/testify/require"
 )
 
 func AssertNoRatelimitAnnotations(
@@ -33,3 +35,24 @@ func AssertNoRatelimitAnnotations(
 		}
 	}
 }
+
+// ExhaustPagination calls the list function with each page token it returns until there are no more pages, and
+// returns every item that was listed.
+func ExhaustPagination[T any](
+	t *testing.T,
+	list func(pToken *pagination.Token) ([]T, string, annotations.Annotations, error),
+) []T {
+	items := make([]T, 0)
+	pToken := &pagination.Token{}
+	for {
+		nextItems, nextToken, listAnnotations, err := list(pToken)
+		require.Nil(t, err)
+		AssertNoRatelimitAnnotations(t, listAnnotations)
+
+		items = append(items, nextItems...)
+		if nextToken == "" {
+			return items
+		}
+		pToken = &pagination.Token{Token: nextToken}
+	}
+}